@@ -0,0 +1,173 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+// The Delaunay refinement below is not part of libtess.js, it is ported from
+// libtess2 (tessMeshRefineDelaunay and tessMeshFlipEdge).
+
+// edgeIsInternal tells whether the given edge, whose left face is inside,
+// separates two inside faces (as opposed to being a boundary edge, which must
+// never be flipped).
+func edgeIsInternal(e *GluHalfEdge) bool {
+	return e.RFace() != nil && e.RFace().Inside
+}
+
+// isTriangle tells whether the given face is bounded by exactly three edges.
+func isTriangle(f *GluFace) bool {
+	return f.AnEdge.LNext.LNext.LNext == f.AnEdge
+}
+
+// inCircle returns a positive value if v lies inside the circle through v0,
+// v1 and v2 (given in counter-clockwise order in the S/T plane), a negative
+// value if it lies outside, and zero if all four are cocircular.
+func inCircle(v, v0, v1, v2 *GluVertex) float64 {
+	var (
+		adx = float64(v0.S) - float64(v.S)
+		ady = float64(v0.T) - float64(v.T)
+		bdx = float64(v1.S) - float64(v.S)
+		bdy = float64(v1.T) - float64(v.T)
+		cdx = float64(v2.S) - float64(v.S)
+		cdy = float64(v2.T) - float64(v.T)
+
+		abdet = adx*bdy - bdx*ady
+		bcdet = bdx*cdy - cdx*bdy
+		cadet = cdx*ady - adx*cdy
+
+		alift = adx*adx + ady*ady
+		blift = bdx*bdx + bdy*bdy
+		clift = cdx*cdx + cdy*cdy
+	)
+	return alift*bcdet + blift*cadet + clift*abdet
+}
+
+// edgeIsLocallyDelaunay tells whether the vertex opposite the given edge in
+// its right face lies outside or on the circumcircle of its left face. Ties
+// count as Delaunay, so that the diagonals of cocircular quadrilaterals (such
+// as rectangles) are left alone instead of being flipped back and forth.
+func edgeIsLocallyDelaunay(e *GluHalfEdge) bool {
+	return inCircle(e.Sym.LNext.LNext.Org, e.LNext.Org, e.LNext.LNext.Org, e.Org) <= 0
+}
+
+// FlipEdge replaces the given edge, which must be the diagonal of the
+// quadrilateral formed by its two triangular faces, with the other diagonal of
+// that quadrilateral. The edge and its faces are reused, so pointers to them
+// remain valid.
+func (g *GluMesh) FlipEdge(edge *GluHalfEdge) {
+	var (
+		a0 = edge
+		a1 = a0.LNext
+		a2 = a1.LNext
+		b0 = edge.Sym
+		b1 = b0.LNext
+		b2 = b1.LNext
+
+		aOrg = a0.Org
+		aOpp = a2.Org
+		bOrg = b0.Org
+		bOpp = b2.Org
+
+		fa = a0.LFace
+		fb = b0.LFace
+	)
+
+	assert(a2.LNext == a0, "a2.LNext == a0")
+	assert(b2.LNext == b0, "b2.LNext == b0")
+
+	a0.Org = bOpp
+	a0.ONext = b1.Sym
+	b0.Org = aOpp
+	b0.ONext = a1.Sym
+	a2.ONext = b0
+	b2.ONext = a0
+	b1.ONext = a2.Sym
+	a1.ONext = b2.Sym
+
+	a0.LNext = a2
+	a2.LNext = b1
+	b1.LNext = a0
+
+	b0.LNext = b2
+	b2.LNext = a1
+	a1.LNext = b0
+
+	a1.LFace = fb
+	b1.LFace = fa
+
+	fa.AnEdge = a0
+	fb.AnEdge = b0
+
+	if aOrg.AnEdge == a0 {
+		aOrg.AnEdge = b1
+	}
+	if bOrg.AnEdge == b0 {
+		bOrg.AnEdge = a1
+	}
+
+	if DEBUG {
+		for _, e := range []*GluHalfEdge{a0, a1, a2, b0, b1, b2} {
+			assert(e.LNext.ONext.Sym == e, "e.LNext.ONext.Sym == e")
+			assert(e.ONext.Sym.LNext == e, "e.ONext.Sym.LNext == e")
+			assert(e.Org.AnEdge.Org == e.Org, "e.Org.AnEdge.Org == e.Org")
+		}
+	}
+}
+
+// RefineDelaunay improves a triangulation of the inside faces of the mesh by
+// flipping interior edges until every pair of adjacent inside triangles
+// passes the in-circle test, which removes most long and thin triangles.
+//
+// Only edges separating two inside triangles are flipped; edges on the
+// boundary between inside and outside faces are left untouched, as are edges
+// of inside faces that are not triangles. The test is performed on the S/T
+// projection of the vertices.
+func (g *GluMesh) RefineDelaunay() {
+	var (
+		stack    []*GluHalfEdge
+		maxFaces int
+	)
+	for f := range g.Faces() {
+		if !f.Inside {
+			continue
+		}
+		for e := range f.Edges() {
+			e.mark = edgeIsInternal(e)
+			if e.mark && !e.Sym.mark {
+				stack = append(stack, e)
+			}
+		}
+		maxFaces++
+	}
+
+	// The algorithm should converge in O(n^2) flips; the limit guards against
+	// cycling when round-off makes the in-circle test inconsistent for nearly
+	// cocircular vertices.
+	maxIter := maxFaces * maxFaces
+
+	// Pop edges until we find one which is not locally Delaunay, flip it, and
+	// push any of the four opposite edges which are internal and not already
+	// on the stack.
+	for iter := 0; len(stack) > 0 && iter < maxIter; iter++ {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		e.mark, e.Sym.mark = false, false
+
+		if !isTriangle(e.LFace) || !isTriangle(e.RFace()) || edgeIsLocallyDelaunay(e) {
+			continue
+		}
+		g.FlipEdge(e)
+
+		for _, o := range [4]*GluHalfEdge{e.LNext, e.LPrev(), e.Sym.LNext, e.Sym.LPrev()} {
+			if !o.mark && edgeIsInternal(o) {
+				o.mark, o.Sym.mark = true, true
+				stack = append(stack, o)
+			}
+		}
+	}
+
+	// Clear marks left behind if the iteration limit was hit.
+	for _, e := range stack {
+		e.mark, e.Sym.mark = false, false
+	}
+}
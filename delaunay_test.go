@@ -0,0 +1,61 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import "testing"
+
+// edgeEnds returns the end points of every edge of the mesh.
+func edgeEnds(g *GluMesh) map[*GluHalfEdge][2]*GluVertex {
+	ends := make(map[*GluHalfEdge][2]*GluVertex)
+	for e := range g.Edges() {
+		ends[e] = [2]*GluVertex{e.Org, e.Dst()}
+	}
+	return ends
+}
+
+func TestRefineDelaunayCocircular(t *testing.T) {
+	// Every quadrilateral of the grid is a square, so its diagonals are
+	// cocircular ties and the triangulation is already Delaunay.
+	g := gridMesh(3)
+	before := edgeEnds(g)
+	g.RefineDelaunay()
+	checkMesh(t, g)
+	for e, ends := range edgeEnds(g) {
+		if ends != before[e] {
+			t.Errorf("edge (%v,%v)-(%v,%v) was flipped", before[e][0].S, before[e][0].T, before[e][1].S, before[e][1].T)
+		}
+	}
+}
+
+func TestRefineDelaunayFlip(t *testing.T) {
+	// A thin parallelogram split along its long diagonal.
+	g := newTestMesh(
+		[][2]float32{{0, 0}, {10, 0}, {11, 1}, {1, 1}},
+		[][3]int{{0, 1, 2}, {0, 2, 3}},
+	)
+	g.RefineDelaunay()
+	checkMesh(t, g)
+
+	n := 0
+	for e := range g.Edges() {
+		if !edgeIsInternal(e) {
+			continue
+		}
+		n++
+		a, b := e.Org, e.Dst()
+		if a.S > b.S {
+			a, b = b, a
+		}
+		if a.S != 1 || a.T != 1 || b.S != 10 || b.T != 0 {
+			t.Errorf("diagonal is (%v,%v)-(%v,%v), want (1,1)-(10,0)", a.S, a.T, b.S, b.T)
+		}
+		if !edgeIsLocallyDelaunay(e) || !edgeIsLocallyDelaunay(e.Sym) {
+			t.Error("flipped diagonal is not locally Delaunay")
+		}
+	}
+	if n != 1 {
+		t.Errorf("got %d internal edges, want 1", n)
+	}
+}
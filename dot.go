@@ -0,0 +1,135 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// DOTOptions controls the graph written by GluMesh.WriteDOT.
+type DOTOptions struct {
+	// Pointers, if true, writes every half-edge as its own node along with its
+	// Org, Sym, ONext, LNext and LFace pointers. Otherwise each edge is drawn
+	// as a single line between its two vertices.
+	Pointers bool
+}
+
+// meshIndex numbers the elements of a mesh in list order, so that they can be
+// referred to by name. Half-edges are numbered in pairs: the edge found in the
+// edge list is 2*i and its Sym is 2*i+1.
+type meshIndex struct {
+	verts map[*GluVertex]int
+	faces map[*GluFace]int
+	edges map[*GluHalfEdge]int
+}
+
+func newMeshIndex(g *GluMesh) *meshIndex {
+	x := &meshIndex{
+		verts: make(map[*GluVertex]int),
+		faces: make(map[*GluFace]int),
+		edges: make(map[*GluHalfEdge]int),
+	}
+	for v := g.VHead.Next; v != nil && v != g.VHead; v = v.Next {
+		x.verts[v] = len(x.verts)
+	}
+	for f := g.FHead.Next; f != nil && f != g.FHead; f = f.Next {
+		x.faces[f] = len(x.faces)
+	}
+	for e := g.EHead.Next; e != nil && e != g.EHead; e = e.Next {
+		x.edges[e] = len(x.edges)
+		if e.Sym != nil {
+			x.edges[e.Sym] = len(x.edges)
+		}
+	}
+	return x
+}
+
+// WriteDOT writes the mesh to w as a Graphviz DOT graph, for debugging.
+//
+// Vertices are labeled with their index and Coords, and faces with their
+// index and Inside flag (inside faces are filled). Pointers which are nil or
+// lead outside of the mesh's lists are drawn to a node named "nil", so that
+// broken meshes can be inspected too. If opts is nil, the zero options are
+// used.
+func (g *GluMesh) WriteDOT(w io.Writer, opts *DOTOptions) error {
+	if opts == nil {
+		opts = &DOTOptions{}
+	}
+	var (
+		x       = newMeshIndex(g)
+		bw      = bufio.NewWriter(w)
+		unknown bool
+	)
+
+	vName := func(v *GluVertex) string {
+		if i, ok := x.verts[v]; ok {
+			return fmt.Sprintf("v%d", i)
+		}
+		unknown = true
+		return "nil"
+	}
+	fName := func(f *GluFace) string {
+		if i, ok := x.faces[f]; ok {
+			return fmt.Sprintf("f%d", i)
+		}
+		unknown = true
+		return "nil"
+	}
+	eName := func(e *GluHalfEdge) string {
+		if i, ok := x.edges[e]; ok {
+			return fmt.Sprintf("e%d", i)
+		}
+		unknown = true
+		return "nil"
+	}
+
+	fmt.Fprintln(bw, "digraph GluMesh {")
+
+	for v := g.VHead.Next; v != nil && v != g.VHead; v = v.Next {
+		fmt.Fprintf(bw, "\t%s [shape=ellipse, label=\"%s\\n(%g, %g, %g)\"];\n",
+			vName(v), vName(v), v.Coords[0], v.Coords[1], v.Coords[2])
+	}
+
+	for f := g.FHead.Next; f != nil && f != g.FHead; f = f.Next {
+		style := ""
+		if f.Inside {
+			style = ", style=filled, fillcolor=lightgray"
+		}
+		fmt.Fprintf(bw, "\t%s [shape=box, label=\"%s\\ninside=%t\"%s];\n", fName(f), fName(f), f.Inside, style)
+	}
+
+	for e := g.EHead.Next; e != nil && e != g.EHead; e = e.Next {
+		if !opts.Pointers {
+			var dst *GluVertex
+			var rFace *GluFace
+			if e.Sym != nil {
+				dst, rFace = e.Sym.Org, e.Sym.LFace
+			}
+			fmt.Fprintf(bw, "\t%s -> %s [dir=none, label=\"%s L=%s R=%s\"];\n",
+				vName(e.Org), vName(dst), eName(e), fName(e.LFace), fName(rFace))
+			continue
+		}
+
+		for _, h := range [2]*GluHalfEdge{e, e.Sym} {
+			if h == nil {
+				continue
+			}
+			fmt.Fprintf(bw, "\t%s [shape=diamond, label=\"%s\\nwinding=%d\"];\n", eName(h), eName(h), h.winding)
+			fmt.Fprintf(bw, "\t%s -> %s [label=\"Org\", color=black];\n", eName(h), vName(h.Org))
+			fmt.Fprintf(bw, "\t%s -> %s [label=\"Sym\", color=gray, style=dashed];\n", eName(h), eName(h.Sym))
+			fmt.Fprintf(bw, "\t%s -> %s [label=\"ONext\", color=blue];\n", eName(h), eName(h.ONext))
+			fmt.Fprintf(bw, "\t%s -> %s [label=\"LNext\", color=red];\n", eName(h), eName(h.LNext))
+			fmt.Fprintf(bw, "\t%s -> %s [label=\"LFace\", color=darkgreen, style=dotted];\n", eName(h), fName(h.LFace))
+		}
+	}
+
+	if unknown {
+		fmt.Fprintln(bw, "\tnil [shape=octagon, color=red];")
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,115 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	dotNode = regexp.MustCompile(`^\t(\w+) \[[^\]]*\];$`)
+	dotEdge = regexp.MustCompile(`^\t(\w+) -> (\w+) \[[^\]]*\];$`)
+)
+
+// parseDOT checks that out is a digraph made of the node and edge statements
+// WriteDOT writes, with every edge between declared nodes, and returns the
+// declared nodes.
+func parseDOT(t *testing.T, out string) map[string]bool {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) < 2 || lines[0] != "digraph GluMesh {" || lines[len(lines)-1] != "}" {
+		t.Fatalf("not a digraph:\n%s", out)
+	}
+	var (
+		nodes = make(map[string]bool)
+		edges [][2]string
+	)
+	for _, l := range lines[1 : len(lines)-1] {
+		if m := dotNode.FindStringSubmatch(l); m != nil {
+			nodes[m[1]] = true
+		} else if m := dotEdge.FindStringSubmatch(l); m != nil {
+			edges = append(edges, [2]string{m[1], m[2]})
+		} else {
+			t.Fatalf("malformed line %q", l)
+		}
+		if strings.Count(l, `"`)%2 != 0 {
+			t.Fatalf("unbalanced quotes in line %q", l)
+		}
+	}
+	for _, e := range edges {
+		if !nodes[e[0]] || !nodes[e[1]] {
+			t.Errorf("edge %s -> %s between undeclared nodes", e[0], e[1])
+		}
+	}
+	return nodes
+}
+
+func TestWriteDOT(t *testing.T) {
+	// gridMesh(1) has 4 vertices, 3 faces (two triangles and the outside)
+	// and 5 edges, that is 10 half-edges.
+	for _, pointers := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := gridMesh(1).WriteDOT(&buf, &DOTOptions{Pointers: pointers}); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		nodes := parseDOT(t, out)
+
+		var want []string
+		for i := 0; i < 4; i++ {
+			want = append(want, fmt.Sprintf("v%d", i))
+		}
+		for i := 0; i < 3; i++ {
+			want = append(want, fmt.Sprintf("f%d", i))
+		}
+		for _, name := range want {
+			if !nodes[name] {
+				t.Errorf("Pointers=%t: no node %s", pointers, name)
+			}
+		}
+		for i := 0; i < 10; i++ {
+			name := fmt.Sprintf("e%d", i)
+			switch {
+			case pointers && !nodes[name]:
+				t.Errorf("Pointers=%t: no node %s", pointers, name)
+			case !pointers && i%2 == 0 && !strings.Contains(out, `label="`+name+` `):
+				// Without pointers, only the half-edge in the edge list
+				// labels each edge.
+				t.Errorf("Pointers=%t: no edge labeled %s", pointers, name)
+			}
+		}
+		if nodes["nil"] {
+			t.Errorf("Pointers=%t: nil node in a consistent mesh", pointers)
+		}
+	}
+}
+
+func TestWriteDOTNil(t *testing.T) {
+	g := gridMesh(1)
+	removeOutsideFaces(g)
+
+	for _, pointers := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := g.WriteDOT(&buf, &DOTOptions{Pointers: pointers}); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		if nodes := parseDOT(t, out); !nodes["nil"] {
+			t.Errorf("Pointers=%t: no nil node", pointers)
+		}
+		// Without pointers, the missing face shows in the edge labels.
+		ref := "=nil"
+		if pointers {
+			ref = "-> nil"
+		}
+		if !strings.Contains(out, ref) {
+			t.Errorf("Pointers=%t: nothing refers to the nil node", pointers)
+		}
+	}
+}
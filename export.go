@@ -0,0 +1,194 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// insideFaces returns the inside faces of the mesh and the vertices they use,
+// along with the index of each such vertex in the returned list.
+func (g *GluMesh) insideFaces() (faces []*GluFace, verts []*GluVertex, index map[*GluVertex]int) {
+	index = make(map[*GluVertex]int)
+	for f := range g.Faces() {
+		if !f.Inside {
+			continue
+		}
+		faces = append(faces, f)
+		for e := range f.Edges() {
+			if _, ok := index[e.Org]; !ok {
+				index[e.Org] = len(verts)
+				verts = append(verts, e.Org)
+			}
+		}
+	}
+	return
+}
+
+// WriteOBJ writes the inside faces of the mesh to w as a Wavefront OBJ file,
+// using the Coords of their vertices. Faces which are not triangles are
+// written as polygons.
+//
+// If texCoord is not nil, it is called with the client Data of each vertex
+// and the result is written as the vertex's texture coordinates.
+func (g *GluMesh) WriteOBJ(w io.Writer, texCoord func(data interface{}) [2]float32) error {
+	var (
+		faces, verts, index = g.insideFaces()
+		bw                  = bufio.NewWriter(w)
+	)
+	for _, v := range verts {
+		fmt.Fprintf(bw, "v %g %g %g\n", v.Coords[0], v.Coords[1], v.Coords[2])
+	}
+	if texCoord != nil {
+		for _, v := range verts {
+			uv := texCoord(v.Data)
+			fmt.Fprintf(bw, "vt %g %g\n", uv[0], uv[1])
+		}
+	}
+	for _, f := range faces {
+		bw.WriteString("f")
+		for e := range f.Edges() {
+			// OBJ indices start at one.
+			i := index[e.Org] + 1
+			if texCoord != nil {
+				fmt.Fprintf(bw, " %d/%d", i, i)
+			} else {
+				fmt.Fprintf(bw, " %d", i)
+			}
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// WritePLY writes the inside faces of the mesh to w as a PLY file, using the
+// Coords of their vertices. If binaryFormat is true the binary little-endian
+// encoding is used, otherwise the ASCII one. Faces which are not triangles
+// are written as polygons.
+func (g *GluMesh) WritePLY(w io.Writer, binaryFormat bool) error {
+	var (
+		faces, verts, index = g.insideFaces()
+		bw                  = bufio.NewWriter(w)
+		sizes               = make([]int, len(faces))
+		countType           = "uchar"
+	)
+	for i, f := range faces {
+		for range f.Edges() {
+			sizes[i]++
+		}
+		if sizes[i] > math.MaxUint8 {
+			countType = "uint"
+		}
+	}
+
+	format := "ascii"
+	if binaryFormat {
+		format = "binary_little_endian"
+	}
+	fmt.Fprintf(bw, "ply\nformat %s 1.0\n", format)
+	fmt.Fprintf(bw, "element vertex %d\nproperty float x\nproperty float y\nproperty float z\n", len(verts))
+	fmt.Fprintf(bw, "element face %d\nproperty list %s int vertex_indices\nend_header\n", len(faces), countType)
+
+	if !binaryFormat {
+		for _, v := range verts {
+			fmt.Fprintf(bw, "%g %g %g\n", v.Coords[0], v.Coords[1], v.Coords[2])
+		}
+		for i, f := range faces {
+			fmt.Fprintf(bw, "%d", sizes[i])
+			for e := range f.Edges() {
+				fmt.Fprintf(bw, " %d", index[e.Org])
+			}
+			bw.WriteString("\n")
+		}
+		return bw.Flush()
+	}
+
+	var buf [4]byte
+	put := func(x uint32) {
+		binary.LittleEndian.PutUint32(buf[:], x)
+		bw.Write(buf[:])
+	}
+	for _, v := range verts {
+		for _, c := range v.Coords {
+			put(math.Float32bits(c))
+		}
+	}
+	for i, f := range faces {
+		if countType == "uchar" {
+			bw.WriteByte(byte(sizes[i]))
+		} else {
+			put(uint32(sizes[i]))
+		}
+		for e := range f.Edges() {
+			put(uint32(index[e.Org]))
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteSTL writes the inside faces of the mesh to w as a binary STL file,
+// using the Coords of their vertices. STL can only hold triangles, so an
+// error is returned (before anything is written) if an inside face is not a
+// triangle.
+func (g *GluMesh) WriteSTL(w io.Writer) error {
+	faces, _, _ := g.insideFaces()
+	for _, f := range faces {
+		if !isTriangle(f) {
+			return errors.New("tess: cannot write non-triangle face to STL")
+		}
+	}
+
+	var (
+		bw     = bufio.NewWriter(w)
+		header [80]byte
+		buf    [4]byte
+	)
+	copy(header[:], "tess")
+	bw.Write(header[:])
+	binary.LittleEndian.PutUint32(buf[:], uint32(len(faces)))
+	bw.Write(buf[:])
+
+	putVec := func(v [3]float32) {
+		for _, c := range v {
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(c))
+			bw.Write(buf[:])
+		}
+	}
+	for _, f := range faces {
+		var (
+			a = f.AnEdge.Org.Coords
+			b = f.AnEdge.LNext.Org.Coords
+			c = f.AnEdge.LNext.LNext.Org.Coords
+		)
+		putVec(triangleNormal(a, b, c))
+		putVec(a)
+		putVec(b)
+		putVec(c)
+
+		// Attribute byte count, unused.
+		bw.Write([]byte{0, 0})
+	}
+	return bw.Flush()
+}
+
+// triangleNormal returns the unit normal of the counter-clockwise triangle a,
+// b, c, or the zero vector if the triangle is degenerate.
+func triangleNormal(a, b, c [3]float32) [3]float32 {
+	var (
+		u = [3]float64{float64(b[0] - a[0]), float64(b[1] - a[1]), float64(b[2] - a[2])}
+		v = [3]float64{float64(c[0] - a[0]), float64(c[1] - a[1]), float64(c[2] - a[2])}
+		n = [3]float64{u[1]*v[2] - u[2]*v[1], u[2]*v[0] - u[0]*v[2], u[0]*v[1] - u[1]*v[0]}
+		l = math.Sqrt(dot(n, n))
+	)
+	if l == 0 {
+		return [3]float32{}
+	}
+	return vec32([3]float64{n[0] / l, n[1] / l, n[2] / l})
+}
@@ -0,0 +1,251 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// exportMesh returns gridMesh(1) with a Z coordinate of S+2T, so that Coords
+// differ from the S/T positions, and the S/T position as client data.
+//
+// Its inside faces are the triangles (1,1) (0,0) (1,0) and (0,1) (0,0)
+// (1,1), in the order Edges visits their vertices. The vertices are written
+// in the order of first use, that is (1,1), (0,0), (1,0) and (0,1).
+func exportMesh() *GluMesh {
+	g := gridMesh(1)
+	for v := range g.Vertices() {
+		v.Coords[2] = v.S + 2*v.T
+		v.Data = [2]float32{v.S, v.T}
+	}
+	return g
+}
+
+// polygonMesh returns a mesh whose only inside face is a regular polygon
+// with n edges.
+func polygonMesh(n int) *GluMesh {
+	var (
+		points = make([][2]float32, n)
+		poly   = make([]int, n)
+	)
+	for i := range points {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = [2]float32{float32(math.Cos(a)), float32(math.Sin(a))}
+		poly[i] = i
+	}
+	return newPolygonMesh(points, [][]int{poly})
+}
+
+func TestWriteOBJ(t *testing.T) {
+	texCoord := func(data interface{}) [2]float32 {
+		st := data.([2]float32)
+		return [2]float32{st[0] / 2, st[1] / 2}
+	}
+	tests := []struct {
+		name     string
+		texCoord func(interface{}) [2]float32
+		want     string
+	}{
+		{"positions", nil, "" +
+			"v 1 1 3\n" +
+			"v 0 0 0\n" +
+			"v 1 0 1\n" +
+			"v 0 1 2\n" +
+			"f 1 2 3\n" +
+			"f 4 2 1\n"},
+		{"texture coordinates", texCoord, "" +
+			"v 1 1 3\n" +
+			"v 0 0 0\n" +
+			"v 1 0 1\n" +
+			"v 0 1 2\n" +
+			"vt 0.5 0.5\n" +
+			"vt 0 0\n" +
+			"vt 0.5 0\n" +
+			"vt 0 0.5\n" +
+			"f 1/1 2/2 3/3\n" +
+			"f 4/4 2/2 1/1\n"},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := exportMesh().WriteOBJ(&buf, tc.texCoord); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s: got\n%swant\n%s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// plyHeader returns the PLY header WritePLY writes for the given format,
+// number of vertices and faces, and type of the face sizes.
+func plyHeader(format string, verts, faces int, countType string) string {
+	return fmt.Sprintf("ply\n"+
+		"format %s 1.0\n"+
+		"element vertex %d\n"+
+		"property float x\n"+
+		"property float y\n"+
+		"property float z\n"+
+		"element face %d\n"+
+		"property list %s int vertex_indices\n"+
+		"end_header\n", format, verts, faces, countType)
+}
+
+func TestWritePLYASCII(t *testing.T) {
+	want := plyHeader("ascii", 4, 2, "uchar") +
+		"1 1 3\n" +
+		"0 0 0\n" +
+		"1 0 1\n" +
+		"0 1 2\n" +
+		"3 0 1 2\n" +
+		"3 3 1 0\n"
+	var buf bytes.Buffer
+	if err := exportMesh().WritePLY(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%swant\n%s", got, want)
+	}
+}
+
+func TestWritePLYBinary(t *testing.T) {
+	var want bytes.Buffer
+	want.WriteString(plyHeader("binary_little_endian", 4, 2, "uchar"))
+	binary.Write(&want, binary.LittleEndian, [4][3]float32{{1, 1, 3}, {0, 0, 0}, {1, 0, 1}, {0, 1, 2}})
+	for _, f := range [2][3]uint32{{0, 1, 2}, {3, 1, 0}} {
+		want.WriteByte(3)
+		binary.Write(&want, binary.LittleEndian, f)
+	}
+
+	var buf bytes.Buffer
+	if err := exportMesh().WritePLY(&buf, true); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want.Bytes()) {
+		t.Errorf("got\n%q\nwant\n%q", buf.Bytes(), want.Bytes())
+	}
+}
+
+func TestWritePLYLargeFace(t *testing.T) {
+	// Face sizes are written as uchar while they fit one, and as uint once a
+	// face has more edges. Edges visits the polygon's vertices starting with
+	// the last one, which is written first, so the face's indices are in
+	// order.
+	for _, tc := range []struct {
+		n         int
+		countType string
+	}{{255, "uchar"}, {256, "uint"}} {
+		var (
+			g    = polygonMesh(tc.n)
+			want bytes.Buffer
+		)
+		want.WriteString(plyHeader("binary_little_endian", tc.n, 1, tc.countType))
+		var coords [][3]float32
+		for v := range g.Vertices() {
+			coords = append(coords, v.Coords)
+		}
+		binary.Write(&want, binary.LittleEndian, coords[tc.n-1])
+		binary.Write(&want, binary.LittleEndian, coords[:tc.n-1])
+		if tc.countType == "uchar" {
+			want.WriteByte(byte(tc.n))
+		} else {
+			binary.Write(&want, binary.LittleEndian, uint32(tc.n))
+		}
+		for i := 0; i < tc.n; i++ {
+			binary.Write(&want, binary.LittleEndian, uint32(i))
+		}
+
+		var buf bytes.Buffer
+		if err := g.WritePLY(&buf, true); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want.Bytes()) {
+			t.Errorf("%d edges: output differs from the expected %s layout", tc.n, tc.countType)
+		}
+
+		// The ASCII encoding has the same header.
+		buf.Reset()
+		if err := g.WritePLY(&buf, false); err != nil {
+			t.Fatal(err)
+		}
+		if header := plyHeader("ascii", tc.n, 1, tc.countType); !strings.HasPrefix(buf.String(), header) {
+			t.Errorf("%d edges: ASCII output does not start with\n%s", tc.n, header)
+		}
+	}
+}
+
+func TestWriteSTL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gridMesh(1).WriteSTL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if want := 84 + 50*2; len(out) != want {
+		t.Fatalf("got %d bytes, want %d", len(out), want)
+	}
+	if !bytes.HasPrefix(out, []byte("tess")) {
+		t.Errorf("header %q does not start with tess", out[:80])
+	}
+	if n := binary.LittleEndian.Uint32(out[80:]); n != 2 {
+		t.Errorf("got %d triangles, want 2", n)
+	}
+
+	var tris [2]struct {
+		Normal    [3]float32
+		Vertices  [3][3]float32
+		Attribute uint16
+	}
+	if err := binary.Read(bytes.NewReader(out[84:]), binary.LittleEndian, &tris); err != nil {
+		t.Fatal(err)
+	}
+	want := [2][3][3]float32{
+		{{1, 1, 0}, {0, 0, 0}, {1, 0, 0}},
+		{{0, 1, 0}, {0, 0, 0}, {1, 1, 0}},
+	}
+	for i, tri := range tris {
+		if tri.Normal != [3]float32{0, 0, 1} {
+			t.Errorf("triangle %d: normal %v, want [0 0 1]", i, tri.Normal)
+		}
+		if tri.Vertices != want[i] {
+			t.Errorf("triangle %d: vertices %v, want %v", i, tri.Vertices, want[i])
+		}
+		if tri.Attribute != 0 {
+			t.Errorf("triangle %d: attribute byte count %d, want 0", i, tri.Attribute)
+		}
+	}
+}
+
+func TestWriteSTLTilted(t *testing.T) {
+	// The triangles of exportMesh lie in the plane z = x + 2y.
+	var buf bytes.Buffer
+	if err := exportMesh().WriteSTL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := [3]float64{-1 / math.Sqrt(6), -2 / math.Sqrt(6), 1 / math.Sqrt(6)}
+	for i := 0; i < 2; i++ {
+		var n [3]float32
+		binary.Read(bytes.NewReader(buf.Bytes()[84+50*i:]), binary.LittleEndian, &n)
+		for j := range n {
+			if math.Abs(float64(n[j])-want[j]) > 1e-6 {
+				t.Errorf("triangle %d: normal %v, want %v", i, n, want)
+				break
+			}
+		}
+	}
+}
+
+func TestWriteSTLNonTriangle(t *testing.T) {
+	var buf bytes.Buffer
+	if err := polygonMesh(4).WriteSTL(&buf); err == nil {
+		t.Error("wrote a quadrilateral to STL")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %d bytes before failing", buf.Len())
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import "math"
+
+// maxFlattenDepth limits the recursive subdivision of curves, so that a zero
+// or otherwise unreachable tolerance cannot recurse forever. At this depth a
+// single curve is split into at most 65536 segments.
+const maxFlattenDepth = 16
+
+// AppendQuadratic appends vertices approximating the quadratic Bezier curve
+// from p0 to p2 with control point p1 to the contour dst, and returns the
+// extended contour.
+//
+// The start point p0 is expected to already be the last vertex of dst and is
+// not appended; the end point p2 always is. No point on the curve lies
+// farther than tol from the polyline formed by p0 and the appended vertices.
+func AppendQuadratic(dst [][3]float32, p0, p1, p2 [3]float32, tol float32) [][3]float32 {
+	// Every quadratic is exactly representable as a cubic.
+	var c1, c2 [3]float32
+	for i := range c1 {
+		c1[i] = p0[i] + 2.0/3.0*(p1[i]-p0[i])
+		c2[i] = p2[i] + 2.0/3.0*(p1[i]-p2[i])
+	}
+	return AppendCubic(dst, p0, c1, c2, p2, tol)
+}
+
+// AppendCubic appends vertices approximating the cubic Bezier curve from p0 to
+// p3 with control points p1 and p2 to the contour dst, and returns the
+// extended contour.
+//
+// The start point p0 is expected to already be the last vertex of dst and is
+// not appended; the end point p3 always is. No point on the curve lies
+// farther than tol from the polyline formed by p0 and the appended vertices.
+func AppendCubic(dst [][3]float32, p0, p1, p2, p3 [3]float32, tol float32) [][3]float32 {
+	return flattenCubic(dst, vec64(p0), vec64(p1), vec64(p2), vec64(p3), float64(tol), 0)
+}
+
+// flattenCubic adaptively subdivides the cubic p0..p3 using de Casteljau's
+// algorithm. A curve lies within the convex hull of its control points, so
+// once both inner control points are within tol of the chord p0-p3 the whole
+// curve is, and the chord is emitted.
+func flattenCubic(dst [][3]float32, p0, p1, p2, p3 [3]float64, tol float64, depth int) [][3]float32 {
+	if depth >= maxFlattenDepth || (segmentDist(p1, p0, p3) <= tol && segmentDist(p2, p0, p3) <= tol) {
+		return append(dst, vec32(p3))
+	}
+	var (
+		p01   = mid(p0, p1)
+		p12   = mid(p1, p2)
+		p23   = mid(p2, p3)
+		p012  = mid(p01, p12)
+		p123  = mid(p12, p23)
+		p0123 = mid(p012, p123)
+	)
+	dst = flattenCubic(dst, p0, p01, p012, p0123, tol, depth+1)
+	return flattenCubic(dst, p0123, p123, p23, p3, tol, depth+1)
+}
+
+// AppendArc appends vertices approximating an elliptical arc to the contour
+// dst, and returns the extended contour.
+//
+// The ellipse is centered at center, has radii rx and ry, and is rotated by
+// rotation radians in the XY plane; all vertices share the Z coordinate of
+// center. The arc starts at angle start and sweeps sweep radians (positive is
+// counter-clockwise), both measured in the unrotated ellipse's parameter
+// space.
+//
+// Unlike AppendQuadratic and AppendCubic, the start point of the arc is
+// appended too, as it is not generally known to the caller. No point on the
+// arc lies farther than tol from the polyline formed by the appended
+// vertices.
+func AppendArc(dst [][3]float32, center [3]float32, rx, ry, rotation, start, sweep, tol float32) [][3]float32 {
+	c := vec64(center)
+	return flattenArc(dst, c, math.Abs(float64(rx)), math.Abs(float64(ry)), float64(rotation), float64(start), float64(sweep), float64(tol), true)
+}
+
+// AppendEndpointArc appends vertices approximating an elliptical arc given in
+// the endpoint form used by SVG path data to the contour dst, and returns the
+// extended contour.
+//
+// The arc runs from p0 to p1 on an ellipse with radii rx and ry, rotated by
+// rotation radians in the XY plane. Of the four candidate arcs, largeArc
+// selects one spanning more than 180 degrees and sweep selects the
+// counter-clockwise one. Radii too small to reach p1 are scaled up, and zero
+// radii or coincident end points degrade to a straight line, both as SVG
+// specifies. As with AppendCubic, p0 is not appended but p1 always is.
+func AppendEndpointArc(dst [][3]float32, p0, p1 [3]float32, rx, ry, rotation float32, largeArc, sweep bool, tol float32) [][3]float32 {
+	var (
+		a   = vec64(p0)
+		b   = vec64(p1)
+		rxf = math.Abs(float64(rx))
+		ryf = math.Abs(float64(ry))
+		phi = float64(rotation)
+	)
+	if rxf == 0 || ryf == 0 || (a[0] == b[0] && a[1] == b[1]) {
+		return append(dst, p1)
+	}
+
+	// Conversion from endpoint to center parameterization, see the SVG
+	// implementation notes (F.6.5 and F.6.6).
+	sin, cos := math.Sincos(phi)
+	dx, dy := (a[0]-b[0])/2, (a[1]-b[1])/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	if l := x1*x1/(rxf*rxf) + y1*y1/(ryf*ryf); l > 1 {
+		l = math.Sqrt(l)
+		rxf *= l
+		ryf *= l
+	}
+
+	num := rxf*rxf*ryf*ryf - rxf*rxf*y1*y1 - ryf*ryf*x1*x1
+	den := rxf*rxf*y1*y1 + ryf*ryf*x1*x1
+	k := math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		k = -k
+	}
+	cx1 := k * rxf * y1 / ryf
+	cy1 := -k * ryf * x1 / rxf
+
+	c := [3]float64{
+		cos*cx1 - sin*cy1 + (a[0]+b[0])/2,
+		sin*cx1 + cos*cy1 + (a[1]+b[1])/2,
+		a[2],
+	}
+
+	theta := math.Atan2((y1-cy1)/ryf, (x1-cx1)/rxf)
+	delta := math.Atan2((-y1-cy1)/ryf, (-x1-cx1)/rxf) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	dst = flattenArc(dst, c, rxf, ryf, phi, theta, delta, float64(tol), false)
+
+	// Land exactly on the requested end point rather than on its rounded
+	// reconstruction.
+	dst[len(dst)-1] = p1
+	return dst
+}
+
+// flattenArc appends the arc as evenly spaced vertices. Under the affine map
+// taking the unit circle onto the ellipse, a chord spanning dt radians stays
+// within 1-cos(dt/2) of the circle, and no distance grows by more than the
+// larger radius; dt is chosen to keep that product within tol.
+func flattenArc(dst [][3]float32, c [3]float64, rx, ry, phi, start, sweep, tol float64, first bool) [][3]float32 {
+	r := math.Max(rx, ry)
+	step := math.Pi
+	if tol > 0 && tol < r {
+		step = 2 * math.Acos(1-tol/r)
+	} else if tol <= 0 {
+		step = 0
+	}
+
+	n := 1 << maxFlattenDepth
+	if step > 0 {
+		if m := math.Ceil(math.Abs(sweep) / step); m < float64(n) {
+			n = int(m)
+		}
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	sin, cos := math.Sincos(phi)
+	point := func(theta float64) [3]float32 {
+		s, c0 := math.Sincos(theta)
+		x, y := rx*c0, ry*s
+		return vec32([3]float64{c[0] + cos*x - sin*y, c[1] + sin*x + cos*y, c[2]})
+	}
+
+	if first {
+		dst = append(dst, point(start))
+	}
+	for i := 1; i <= n; i++ {
+		dst = append(dst, point(start+sweep*float64(i)/float64(n)))
+	}
+	return dst
+}
+
+// segmentDist returns the distance from p to the line segment a-b.
+func segmentDist(p, a, b [3]float64) float64 {
+	var ab, ap [3]float64
+	for i := range ab {
+		ab[i] = b[i] - a[i]
+		ap[i] = p[i] - a[i]
+	}
+	var t float64
+	if l := dot(ab, ab); l > 0 {
+		t = math.Max(0, math.Min(1, dot(ap, ab)/l))
+	}
+	for i := range ap {
+		ap[i] -= t * ab[i]
+	}
+	return math.Sqrt(dot(ap, ap))
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func mid(a, b [3]float64) [3]float64 {
+	return [3]float64{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2, (a[2] + b[2]) / 2}
+}
+
+func vec64(v [3]float32) [3]float64 {
+	return [3]float64{float64(v[0]), float64(v[1]), float64(v[2])}
+}
+
+func vec32(v [3]float64) [3]float32 {
+	return [3]float32{float32(v[0]), float32(v[1]), float32(v[2])}
+}
@@ -0,0 +1,207 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+var flattenTolerances = []float32{1, 0.1, 0.01, 0.001}
+
+// maxDeviation samples the exact curve at n+1 evenly spaced parameters in
+// [0, 1] and returns the largest distance from a sample to the polyline.
+func maxDeviation(curve func(u float64) [3]float64, poly [][3]float32, n int) float64 {
+	var max float64
+	for i := 0; i <= n; i++ {
+		p := curve(float64(i) / float64(n))
+		d := math.Inf(1)
+		for j := 1; j < len(poly); j++ {
+			d = math.Min(d, segmentDist(p, vec64(poly[j-1]), vec64(poly[j])))
+		}
+		max = math.Max(max, d)
+	}
+	return max
+}
+
+// checkDeviation fails the test if the polyline strays farther than tol from
+// the curve, allowing for float32 rounding of the vertices.
+func checkDeviation(t *testing.T, name string, curve func(u float64) [3]float64, poly [][3]float32, tol float32) {
+	t.Helper()
+	if d := maxDeviation(curve, poly, 2000); d > float64(tol)*1.001+1e-5 {
+		t.Errorf("%s: tol=%g: max deviation %g with %d vertices", name, tol, d, len(poly))
+	}
+}
+
+// checkEnds fails the test unless poly starts at start and ends at end.
+func checkEnds(t *testing.T, name string, poly [][3]float32, start, end [3]float32) {
+	t.Helper()
+	if len(poly) < 2 {
+		t.Fatalf("%s: got %d vertices, want at least 2", name, len(poly))
+	}
+	if poly[0] != start || poly[len(poly)-1] != end {
+		t.Errorf("%s: polyline runs from %v to %v, want %v to %v", name, poly[0], poly[len(poly)-1], start, end)
+	}
+}
+
+func TestAppendQuadratic(t *testing.T) {
+	p0, p1, p2 := [3]float32{0, 0, 0}, [3]float32{25, 60, 5}, [3]float32{50, 0, 10}
+	curve := func(u float64) [3]float64 {
+		var p [3]float64
+		for i := range p {
+			p[i] = (1-u)*(1-u)*float64(p0[i]) + 2*u*(1-u)*float64(p1[i]) + u*u*float64(p2[i])
+		}
+		return p
+	}
+	for _, tol := range flattenTolerances {
+		poly := AppendQuadratic([][3]float32{p0}, p0, p1, p2, tol)
+		checkEnds(t, "quadratic", poly, p0, p2)
+		checkDeviation(t, "quadratic", curve, poly, tol)
+	}
+}
+
+func TestAppendCubic(t *testing.T) {
+	tests := []struct {
+		name           string
+		p0, p1, p2, p3 [3]float32
+	}{
+		{"s-curve", [3]float32{0, 0, 0}, [3]float32{10, 40, 0}, [3]float32{50, -30, 0}, [3]float32{60, 10, 0}},
+		{"cusp", [3]float32{0, 0, 0}, [3]float32{60, 30, 0}, [3]float32{0, 30, 0}, [3]float32{60, 0, 0}},
+		{"loop", [3]float32{0, 0, 0}, [3]float32{80, 50, 0}, [3]float32{-20, 50, 0}, [3]float32{60, 0, 0}},
+		{"3d", [3]float32{0, 0, 0}, [3]float32{10, 20, 30}, [3]float32{40, -10, 20}, [3]float32{50, 5, -5}},
+		{"straight", [3]float32{0, 0, 0}, [3]float32{1, 1, 0}, [3]float32{2, 2, 0}, [3]float32{3, 3, 0}},
+	}
+	for _, tc := range tests {
+		curve := func(u float64) [3]float64 {
+			var p [3]float64
+			for i := range p {
+				p[i] = math.Pow(1-u, 3)*float64(tc.p0[i]) + 3*u*(1-u)*(1-u)*float64(tc.p1[i]) +
+					3*u*u*(1-u)*float64(tc.p2[i]) + u*u*u*float64(tc.p3[i])
+			}
+			return p
+		}
+		for _, tol := range flattenTolerances {
+			poly := AppendCubic([][3]float32{tc.p0}, tc.p0, tc.p1, tc.p2, tc.p3, tol)
+			checkEnds(t, tc.name, poly, tc.p0, tc.p3)
+			checkDeviation(t, tc.name, curve, poly, tol)
+		}
+	}
+}
+
+// ellipse returns the exact elliptical arc with the given center, radii and
+// rotation, starting at angle start and sweeping sweep radians.
+func ellipse(c [3]float64, rx, ry, rotation, start, sweep float64) func(u float64) [3]float64 {
+	sin, cos := math.Sincos(rotation)
+	return func(u float64) [3]float64 {
+		s, c0 := math.Sincos(start + sweep*u)
+		x, y := rx*c0, ry*s
+		return [3]float64{c[0] + cos*x - sin*y, c[1] + sin*x + cos*y, c[2]}
+	}
+}
+
+func TestAppendArc(t *testing.T) {
+	tests := []struct {
+		name                           string
+		center                         [3]float32
+		rx, ry, rotation, start, sweep float32
+	}{
+		{"circle", [3]float32{5, 5, 0}, 10, 10, 0, 0, 2 * math.Pi},
+		{"ellipse", [3]float32{0, 0, 3}, 20, 5, math.Pi / 6, 0.5, 4},
+		{"clockwise", [3]float32{-5, 2, 0}, 8, 12, -1, 2, -3},
+		{"tiny", [3]float32{0, 0, 0}, 0.01, 0.01, 0, 0, math.Pi},
+	}
+	for _, tc := range tests {
+		curve := ellipse(vec64(tc.center), float64(tc.rx), float64(tc.ry), float64(tc.rotation), float64(tc.start), float64(tc.sweep))
+		for _, tol := range flattenTolerances {
+			poly := AppendArc(nil, tc.center, tc.rx, tc.ry, tc.rotation, tc.start, tc.sweep, tol)
+			checkDeviation(t, tc.name, curve, poly, tol)
+		}
+	}
+}
+
+func TestAppendEndpointArc(t *testing.T) {
+	// A circle of radius 10 through (0, 0) and (10, 0) has its center either
+	// above or below the chord; the arcs around each center span 60 or 300
+	// degrees.
+	var (
+		p0    = [3]float32{0, 0, 0}
+		p1    = [3]float32{10, 0, 0}
+		h     = math.Sqrt(75)
+		above = [3]float64{5, h, 0}
+		below = [3]float64{5, -h, 0}
+		third = math.Pi / 3
+	)
+	tests := []struct {
+		name            string
+		largeArc, sweep bool
+		center          [3]float64
+		start, delta    float64
+	}{
+		{"small ccw", false, true, above, -2 * third, third},
+		{"large ccw", true, true, below, 2 * third, 5 * third},
+		{"small cw", false, false, below, 2 * third, -third},
+		{"large cw", true, false, above, -2 * third, -5 * third},
+	}
+	for _, tc := range tests {
+		curve := ellipse(tc.center, 10, 10, 0, tc.start, tc.delta)
+		for _, tol := range flattenTolerances {
+			poly := AppendEndpointArc([][3]float32{p0}, p0, p1, 10, 10, 0, tc.largeArc, tc.sweep, tol)
+			checkEnds(t, tc.name, poly, p0, p1)
+			checkDeviation(t, tc.name, curve, poly, tol)
+		}
+	}
+}
+
+func TestAppendEndpointArcRotated(t *testing.T) {
+	// An ellipse with radii 20 and 10 rotated by 90 degrees, centered at the
+	// origin, passes through (0, -20) and (10, 0).
+	var (
+		p0    = [3]float32{0, -20, 0}
+		p1    = [3]float32{10, 0, 0}
+		curve = ellipse([3]float64{}, 20, 10, math.Pi/2, -math.Pi, math.Pi/2)
+	)
+	for _, tol := range flattenTolerances {
+		poly := AppendEndpointArc([][3]float32{p0}, p0, p1, 20, 10, math.Pi/2, false, true, tol)
+		checkEnds(t, "rotated", poly, p0, p1)
+		checkDeviation(t, "rotated", curve, poly, tol)
+	}
+}
+
+func TestAppendEndpointArcScaledRadii(t *testing.T) {
+	// Radii too small to span the end points are scaled up, here to a
+	// half-circle of radius 5 around (5, 0).
+	var (
+		p0    = [3]float32{0, 0, 0}
+		p1    = [3]float32{10, 0, 0}
+		curve = ellipse([3]float64{5, 0, 0}, 5, 5, 0, math.Pi, math.Pi)
+	)
+	for _, tol := range flattenTolerances {
+		poly := AppendEndpointArc([][3]float32{p0}, p0, p1, 1, 1, 0, false, true, tol)
+		checkEnds(t, "scaled", poly, p0, p1)
+		checkDeviation(t, "scaled", curve, poly, tol)
+	}
+}
+
+func TestAppendEndpointArcDegenerate(t *testing.T) {
+	var (
+		p0 = [3]float32{1, 2, 0}
+		p1 = [3]float32{4, 6, 0}
+	)
+	tests := []struct {
+		name   string
+		end    [3]float32
+		rx, ry float32
+	}{
+		{"zero rx", p1, 0, 5},
+		{"zero ry", p1, 5, 0},
+		{"coincident", p0, 5, 5},
+	}
+	for _, tc := range tests {
+		poly := AppendEndpointArc([][3]float32{p0}, p0, tc.end, tc.rx, tc.ry, 0, false, true, 0.1)
+		if len(poly) != 2 || poly[1] != tc.end {
+			t.Errorf("%s: got %v, want a straight line to %v", tc.name, poly, tc.end)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseGeoJSON reads the polygons of a GeoJSON document (RFC 7946) into
+// contours to be filled with WindingPositive. The document may be a Polygon
+// or MultiPolygon geometry, a GeometryCollection, a Feature or a
+// FeatureCollection; geometries of other types enclose no area and are
+// skipped, as are features without a geometry.
+//
+// Each ring of a polygon becomes one contour, without its closing position,
+// which must repeat the first one. As RFC 7946 requires, exterior rings are
+// oriented counter-clockwise and holes clockwise, reversing rings as needed;
+// so under WindingPositive the holes are cut from their polygon, overlapping
+// polygons are merged and the parts of a hole outside its polygon are not
+// filled, which repairs many invalid geometries. Coordinates beyond the
+// second one, such as altitudes, are dropped.
+//
+// Feeding the contours to the BeginContour and AddVertex calls of
+// GluTesselator, and writing its boundary-only output back as polygons, is
+// left until it is ported; until then the contours can be used with
+// RasterizeContours and GluMesh.Validate.
+func ParseGeoJSON(data []byte) ([][][2]float32, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("tess: invalid GeoJSON: %v", err)
+	}
+	return obj.appendContours(nil)
+}
+
+// geoJSONObject holds the members of GeoJSON objects which ParseGeoJSON uses.
+type geoJSONObject struct {
+	Type        string           `json:"type"`
+	Coordinates json.RawMessage  `json:"coordinates"`
+	Geometry    *geoJSONObject   `json:"geometry"`
+	Geometries  []*geoJSONObject `json:"geometries"`
+	Features    []*geoJSONObject `json:"features"`
+}
+
+// appendContours appends the contours of the polygons in the object to dst.
+func (o *geoJSONObject) appendContours(dst [][][2]float32) ([][][2]float32, error) {
+	var err error
+	switch o.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(o.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("tess: invalid GeoJSON Polygon coordinates: %v", err)
+		}
+		return appendGeoJSONPolygon(dst, rings)
+
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(o.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("tess: invalid GeoJSON MultiPolygon coordinates: %v", err)
+		}
+		for _, rings := range polygons {
+			if dst, err = appendGeoJSONPolygon(dst, rings); err != nil {
+				return nil, err
+			}
+		}
+
+	case "GeometryCollection", "FeatureCollection", "Feature":
+		children := o.Geometries
+		switch o.Type {
+		case "FeatureCollection":
+			children = o.Features
+		case "Feature":
+			children = []*geoJSONObject{o.Geometry}
+		}
+		for _, c := range children {
+			if c == nil {
+				continue // A feature without a geometry.
+			}
+			if dst, err = c.appendContours(dst); err != nil {
+				return nil, err
+			}
+		}
+
+	case "Point", "MultiPoint", "LineString", "MultiLineString":
+		// No area to fill.
+
+	default:
+		return nil, fmt.Errorf("tess: invalid GeoJSON object type %q", o.Type)
+	}
+	return dst, nil
+}
+
+// appendGeoJSONPolygon appends the contours of the rings of a GeoJSON polygon
+// to dst.
+func appendGeoJSONPolygon(dst [][][2]float32, rings [][][]float64) ([][][2]float32, error) {
+	for i, positions := range rings {
+		ring := make([][2]float32, len(positions))
+		for j, p := range positions {
+			if len(p) < 2 {
+				return nil, fmt.Errorf("tess: invalid GeoJSON position with %d coordinates", len(p))
+			}
+			ring[j] = [2]float32{float32(p[0]), float32(p[1])}
+		}
+		c, err := ringContour(ring, i > 0)
+		if err != nil {
+			return nil, fmt.Errorf("tess: invalid GeoJSON polygon: %v", err)
+		}
+		dst = append(dst, c)
+	}
+	return dst, nil
+}
+
+// ringContour returns the contour of a closed ring of a polygon, that is the
+// ring without its closing position, oriented counter-clockwise for an
+// exterior ring and clockwise for a hole.
+func ringContour(ring [][2]float32, hole bool) ([][2]float32, error) {
+	if len(ring) < 4 {
+		return nil, fmt.Errorf("ring has %d positions, want at least 4", len(ring))
+	}
+	if ring[0] != ring[len(ring)-1] {
+		return nil, errors.New("ring is not closed")
+	}
+	c := ring[:len(ring)-1]
+	if a := contourArea(c); (a < 0 && !hole) || (a > 0 && hole) {
+		for i, j := 0, len(c)-1; i < j; i, j = i+1, j-1 {
+			c[i], c[j] = c[j], c[i]
+		}
+	}
+	return c, nil
+}
+
+// contourArea returns the signed area of a contour, which is positive if it
+// runs counter-clockwise.
+func contourArea(c [][2]float32) float64 {
+	var a float64
+	for i, p := range c {
+		q := c[(i+1)%len(c)]
+		a += float64(p[0])*float64(q[1]) - float64(q[0])*float64(p[1])
+	}
+	return a / 2
+}
+
+// WKTError is returned by ParseWKT for malformed well-known text.
+type WKTError struct {
+	// Offset is the byte offset in the text at which the problem was found.
+	Offset int
+
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *WKTError) Error() string {
+	return fmt.Sprintf("tess: invalid WKT at offset %d: %s", e.Offset, e.Reason)
+}
+
+// ParseWKT reads a POLYGON or MULTIPOLYGON in well-known text into contours
+// to be filled with WindingPositive. Keywords are case-insensitive, and the
+// Z, M and ZM dimensions as well as EMPTY geometries are accepted. Rings
+// become contours as described for ParseGeoJSON.
+func ParseWKT(s string) ([][][2]float32, error) {
+	p := wktParser{s: s}
+	contours, err := p.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.i != len(s) {
+		return nil, p.errorf("unexpected %q after the geometry", s[p.i])
+	}
+	return contours, nil
+}
+
+// wktParser scans well-known text.
+type wktParser struct {
+	s string
+	i int
+
+	// dims is the number of coordinates of each point, or zero if the text
+	// does not specify it.
+	dims int
+}
+
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return &WKTError{Offset: p.i, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (p *wktParser) skipSpace() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\n\r", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// word scans a keyword and returns it in upper case, or returns an empty
+// string if no keyword follows.
+func (p *wktParser) word() string {
+	p.skipSpace()
+	j := p.i
+	for j < len(p.s) && ('A' <= p.s[j]&^0x20 && p.s[j]&^0x20 <= 'Z') {
+		j++
+	}
+	w := strings.ToUpper(p.s[p.i:j])
+	p.i = j
+	return w
+}
+
+// consume skips white space and then c, telling whether c was there.
+func (p *wktParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.i < len(p.s) && p.s[p.i] == c {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) expect(c byte) error {
+	if !p.consume(c) {
+		return p.errorf("expected %q", c)
+	}
+	return nil
+}
+
+// geometry scans a POLYGON or MULTIPOLYGON and returns its contours.
+func (p *wktParser) geometry() ([][][2]float32, error) {
+	p.skipSpace()
+	start := p.i
+	kind := p.word()
+	if kind != "POLYGON" && kind != "MULTIPOLYGON" {
+		p.i = start
+		return nil, p.errorf("expected POLYGON or MULTIPOLYGON")
+	}
+	p.skipSpace()
+	start = p.i
+	switch p.word() {
+	case "Z", "M":
+		p.dims = 3
+	case "ZM":
+		p.dims = 4
+	default:
+		p.i = start
+	}
+
+	if kind == "POLYGON" {
+		return p.polygon(nil)
+	}
+	p.skipSpace()
+	if start = p.i; p.word() == "EMPTY" {
+		return nil, nil
+	}
+	p.i = start
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var (
+		contours [][][2]float32
+		err      error
+	)
+	for {
+		if contours, err = p.polygon(contours); err != nil {
+			return nil, err
+		}
+		if !p.consume(',') {
+			break
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return contours, nil
+}
+
+// polygon scans the rings of a polygon and appends their contours to dst.
+func (p *wktParser) polygon(dst [][][2]float32) ([][][2]float32, error) {
+	p.skipSpace()
+	start := p.i
+	if p.word() == "EMPTY" {
+		return dst, nil
+	}
+	p.i = start
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	for hole := false; ; hole = true {
+		p.skipSpace()
+		ringStart := p.i
+		ring, err := p.ring()
+		if err != nil {
+			return nil, err
+		}
+		c, err := ringContour(ring, hole)
+		if err != nil {
+			return nil, &WKTError{Offset: ringStart, Reason: err.Error()}
+		}
+		dst = append(dst, c)
+		if !p.consume(',') {
+			break
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// ring scans the points of a ring.
+func (p *wktParser) ring() ([][2]float32, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var ring [][2]float32
+	for {
+		v, err := p.point()
+		if err != nil {
+			return nil, err
+		}
+		ring = append(ring, v)
+		if !p.consume(',') {
+			break
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// point scans the coordinates of a point and returns its first two.
+func (p *wktParser) point() ([2]float32, error) {
+	p.skipSpace()
+	var (
+		v     [2]float32
+		n     int
+		start = p.i
+	)
+	for {
+		p.skipSpace()
+		if p.i == len(p.s) || p.s[p.i] == ',' || p.s[p.i] == ')' {
+			break
+		}
+		j := p.i
+		for j < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[j]) >= 0 {
+			j++
+		}
+		x, err := strconv.ParseFloat(p.s[p.i:j], 32)
+		if err != nil {
+			return v, p.errorf("expected a number")
+		}
+		if n < 2 {
+			v[n] = float32(x)
+		}
+		n++
+		p.i = j
+	}
+	if (p.dims != 0 && n != p.dims) || n < 2 || n > 4 {
+		want := "2 to 4"
+		if p.dims != 0 {
+			want = strconv.Itoa(p.dims)
+		}
+		p.i = start
+		return v, p.errorf("point has %d coordinates, want %s", n, want)
+	}
+	return v, nil
+}
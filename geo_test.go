@@ -0,0 +1,179 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var (
+	// A square with a square hole, as contours oriented per RFC 7946.
+	geoSquare = [][2]float32{{0, 0}, {4, 0}, {4, 4}, {0, 4}}
+	geoHole   = [][2]float32{{1, 1}, {1, 3}, {3, 3}, {3, 1}}
+
+	// A square overlapping geoSquare.
+	geoOther = [][2]float32{{2, 2}, {6, 2}, {6, 6}, {2, 6}}
+)
+
+func TestParseGeoJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want [][][2]float32
+	}{
+		{"polygon", `{"type": "Polygon", "coordinates": [
+			[[0, 0], [4, 0], [4, 4], [0, 4], [0, 0]],
+			[[1, 1], [1, 3], [3, 3], [3, 1], [1, 1]]]}`,
+			[][][2]float32{geoSquare, geoHole}},
+		// The same polygon with both rings the wrong way around, and with
+		// altitudes.
+		{"reversed rings", `{"type": "Polygon", "coordinates": [
+			[[0, 0, 9], [0, 4, 9], [4, 4, 9], [4, 0, 9], [0, 0, 9]],
+			[[1, 1], [3, 1], [3, 3], [1, 3], [1, 1]]]}`,
+			[][][2]float32{
+				{{4, 0}, {4, 4}, {0, 4}, {0, 0}},
+				{{1, 3}, {3, 3}, {3, 1}, {1, 1}},
+			}},
+		{"multipolygon", `{"type": "MultiPolygon", "coordinates": [
+			[[[0, 0], [4, 0], [4, 4], [0, 4], [0, 0]]],
+			[[[2, 2], [6, 2], [6, 6], [2, 6], [2, 2]]]]}`,
+			[][][2]float32{geoSquare, geoOther}},
+		{"feature collection", `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"name": "a"}, "geometry":
+				{"type": "Polygon", "coordinates": [[[0, 0], [4, 0], [4, 4], [0, 4], [0, 0]]]}},
+			{"type": "Feature", "properties": null, "geometry": null},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}},
+			{"type": "Feature", "geometry": {"type": "GeometryCollection", "geometries": [
+				{"type": "LineString", "coordinates": [[0, 0], [1, 1]]},
+				{"type": "Polygon", "coordinates": [[[2, 2], [6, 2], [6, 6], [2, 6], [2, 2]]]}]}}]}`,
+			[][][2]float32{geoSquare, geoOther}},
+		{"empty polygon", `{"type": "Polygon", "coordinates": []}`, nil},
+		{"empty collection", `{"type": "FeatureCollection", "features": []}`, nil},
+	}
+	for _, tc := range tests {
+		got, err := ParseGeoJSON([]byte(tc.doc))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseGeoJSONErrors(t *testing.T) {
+	for _, doc := range []string{
+		`{"type": "Polygon", "coordinates": [[[0, 0], [4, 0], [4, 4], [0, 4]]]}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [4, 0], [0, 0]]]}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [4], [4, 4], [0, 0]]]}`,
+		`{"type": "Polygon", "coordinates": [[0, 0], [4, 0], [4, 4], [0, 0]]}`,
+		`{"type": "Polygon"}`,
+		`{"type": "MultiPolygon", "coordinates": [[[0, 0], [4, 0], [4, 4], [0, 0]]]}`,
+		`{"type": "Feature", "geometry": {"type": "Circle"}}`,
+		`{"coordinates": []}`,
+		`{"type": "Polygon", "coordinates": [`,
+		`[]`,
+	} {
+		if c, err := ParseGeoJSON([]byte(doc)); err == nil {
+			t.Errorf("%s: got %v, want an error", doc, c)
+		}
+	}
+}
+
+func TestParseWKT(t *testing.T) {
+	tests := []struct {
+		s    string
+		want [][][2]float32
+	}{
+		{"POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 1 3, 3 3, 3 1, 1 1))", [][][2]float32{geoSquare, geoHole}},
+		{"polygon((0 0,4 0,4 4,0 4,0 0),(1 1,1 3,3 3,3 1,1 1))", [][][2]float32{geoSquare, geoHole}},
+		// Both rings the wrong way around.
+		{"POLYGON ((0 0, 0 4, 4 4, 4 0, 0 0), (1 1, 3 1, 3 3, 1 3, 1 1))", [][][2]float32{
+			{{4, 0}, {4, 4}, {0, 4}, {0, 0}},
+			{{1, 3}, {3, 3}, {3, 1}, {1, 1}},
+		}},
+		{"POLYGON Z ((0 0 1, 4 0 1, 4 4 1, 0 4 1, 0 0 1))", [][][2]float32{geoSquare}},
+		{"POLYGON ZM ((0 0 1 2, 4 0 1 2, 4 4 1 2, 0 4 1 2, 0 0 1 2))", [][][2]float32{geoSquare}},
+		{"POLYGON ((0 0 1, 4 0 1, 4 4 1, 0 4 1, 0 0 1))", [][][2]float32{geoSquare}},
+		{"POLYGON ((0 0, 4e0 0, 4 4.0, +0 4, -0 0))", [][][2]float32{geoSquare}},
+		{"MULTIPOLYGON (((0 0, 4 0, 4 4, 0 4, 0 0)), ((2 2, 6 2, 6 6, 2 6, 2 2)))", [][][2]float32{geoSquare, geoOther}},
+		{"MULTIPOLYGON (EMPTY, ((0 0, 4 0, 4 4, 0 4, 0 0)))", [][][2]float32{geoSquare}},
+		{" \tMultiPolygon M (((0 0 5, 4 0 5, 4 4 5, 0 4 5, 0 0 5)))\n", [][][2]float32{geoSquare}},
+		{"POLYGON EMPTY", nil},
+		{"POLYGON Z EMPTY", nil},
+		{"MULTIPOLYGON EMPTY", nil},
+	}
+	for _, tc := range tests {
+		got, err := ParseWKT(tc.s)
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %v, want %v", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	tests := []struct {
+		s      string
+		offset int
+	}{
+		{"POINT (1 2)", 0},
+		{"", 0},
+		{"POLYGON ((0 0, 1 0, 0 0))", 9},
+		{"POLYGON ((0 0, 1 0, 1 1, 0 1))", 9},
+		{"POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 2 1, 1 1))", 36},
+		{"POLYGON Z ((0 0, 1 0, 1 1, 0 0))", 12},
+		{"POLYGON ((0 0, 1, 1 1, 0 0))", 15},
+		{"POLYGON ((0 0, 1 0 0 0 0, 1 1, 0 0))", 15},
+		{"POLYGON ((0 0, 1 x, 1 1, 0 0))", 17},
+		{"POLYGON ((0 0, 1 0, 1 1, 0 0)", 29},
+		{"POLYGON ((0 0, 1 0, 1 1, 0 0)) x", 31},
+		{"POLYGON (0 0, 1 0, 1 1, 0 0)", 9},
+		{"MULTIPOLYGON ((0 0, 1 0, 1 1, 0 0))", 15},
+	}
+	for _, tc := range tests {
+		_, err := ParseWKT(tc.s)
+		var werr *WKTError
+		if !errors.As(err, &werr) {
+			t.Errorf("%q: got error %v, want a *WKTError", tc.s, err)
+			continue
+		}
+		if werr.Offset != tc.offset {
+			t.Errorf("%q: got offset %d (%v), want %d", tc.s, werr.Offset, err, tc.offset)
+		}
+	}
+}
+
+func TestParseGeoFill(t *testing.T) {
+	// Under WindingPositive, holes are cut regardless of the orientation of
+	// the input rings, overlapping polygons merge, and the part of a hole
+	// outside its polygon is ignored.
+	tests := []struct {
+		name string
+		wkt  string
+		area float64
+	}{
+		{"hole", "POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 1 3, 3 3, 3 1, 1 1))", 12},
+		{"reversed hole", "POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 3 1, 3 3, 1 3, 1 1))", 12},
+		{"overlap", "MULTIPOLYGON (((0 0, 4 0, 4 4, 0 4, 0 0)), ((2 2, 6 2, 6 6, 2 6, 2 2)))", 28},
+		{"hole sticking out", "POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (2 2, 6 2, 6 6, 2 6, 2 2))", 12},
+	}
+	for _, tc := range tests {
+		c, err := ParseWKT(tc.wkt)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if area := insideArea(c, WindingPositive); math.Abs(area-tc.area) > 1e-9 {
+			t.Errorf("%s: area %g, want %g", tc.name, area, tc.area)
+		}
+	}
+}
@@ -63,6 +63,9 @@ type GluHalfEdge struct {
 	// winding is the change in winding number when crossing from the right
 	// face to the left face.
 	winding int
+
+	// mark is used by the edge flip algorithm (see RefineDelaunay).
+	mark bool
 }
 
 // NewGluHalfEdge returns a new and initialized *GluHalfEdge.
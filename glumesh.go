@@ -24,10 +24,10 @@ type GluMesh struct {
 // It has no edges, no vertices, and no loops (what we usually call a "face").
 func NewGluMesh() *GluMesh {
 	m := &GluMesh{
-		VHead:    &GluVertex{},
-		FHead:    &GluFace{},
-		EHead:    &GluHalfEdge{},
-		EHeadSym: &GluHalfEdge{},
+		VHead:    NewGluVertex(nil, nil),
+		FHead:    NewGluFace(nil, nil),
+		EHead:    NewGluHalfEdge(nil),
+		EHeadSym: NewGluHalfEdge(nil),
 	}
 
 	// Pair the half edge head and it's symmetrical counterpart together.
@@ -136,4 +136,10 @@ func (g *GluMesh) Check() {
 		ePrev = e
 	}
 	assert(e.Sym.Next == ePrev.Sym && e.Sym == g.EHeadSym && e.Sym.Sym == e && e.Org == nil && e.Dst() == nil && e.LFace == nil && e.RFace() == nil, "")
+
+	// Each connected piece of a closed mesh is a topological sphere, with an
+	// Euler characteristic of two.
+	if g.closed() {
+		assert(g.EulerCharacteristic() == 2*g.components(), "g.EulerCharacteristic() == 2*g.components()")
+	}
 }
@@ -0,0 +1,233 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import "math"
+
+// Locator answers point location queries against the inside triangles of a
+// mesh, in the S/T plane. Create one using NewLocator.
+//
+// A uniform grid over the triangles lists the triangles overlapping each cell.
+// The first of them in the query point's cell is the start of a walk across
+// edges shared by inside triangles, towards the point; where the walk fails,
+// as it can in non-convex regions, the cell's list is searched. The Locator does not observe
+// changes to the mesh, it must be recreated after the mesh is modified. It is
+// safe for concurrent use.
+type Locator struct {
+	// min is the S/T position of the grid's lower-left corner, and size the
+	// size of a single cell. max is the upper-right corner of the triangles'
+	// bounding box.
+	min, max, size [2]float64
+
+	cols, rows int
+
+	// cells lists, for each grid cell in row-major order, the inside
+	// triangles which overlap it.
+	cells [][]*GluFace
+
+	// maxSteps limits the walk, after which the cell is searched instead.
+	maxSteps int
+}
+
+// NewLocator returns a new *Locator for the inside faces of the given mesh.
+// Inside faces which are not triangles are ignored.
+func NewLocator(m *GluMesh) *Locator {
+	var (
+		tris     []*GluFace
+		min, max = emptySTBounds()
+	)
+	for f := range m.Faces() {
+		if f.Inside && isTriangle(f) {
+			tris = append(tris, f)
+			fMin, fMax := f.STBounds()
+			for i := range min {
+				min[i] = float32(math.Min(float64(min[i]), float64(fMin[i])))
+				max[i] = float32(math.Max(float64(max[i]), float64(fMax[i])))
+			}
+		}
+	}
+
+	l := &Locator{}
+	if len(tris) == 0 {
+		return l
+	}
+
+	// Aim for roughly one triangle per cell, with square-ish cells.
+	var (
+		w = math.Max(float64(max[0])-float64(min[0]), 1e-30)
+		h = math.Max(float64(max[1])-float64(min[1]), 1e-30)
+		n = float64(len(tris))
+	)
+	l.cols = int(math.Max(1, math.Min(n, math.Ceil(math.Sqrt(n*w/h)))))
+	l.rows = int(math.Max(1, math.Min(n, math.Ceil(n/float64(l.cols)))))
+	l.min = [2]float64{float64(min[0]), float64(min[1])}
+	l.max = [2]float64{float64(max[0]), float64(max[1])}
+	l.size = [2]float64{w / float64(l.cols), h / float64(l.rows)}
+	l.cells = make([][]*GluFace, l.cols*l.rows)
+	l.maxSteps = 4*(l.cols+l.rows) + 16
+
+	// Add each triangle to the cells it overlaps, row by row. The rows and the
+	// triangle's extent within them are widened slightly, so that rounding
+	// in cell cannot place a point of the triangle in a cell which lacks it.
+	var (
+		ds = 1e-6 * l.size[0]
+		dt = 1e-6 * l.size[1]
+	)
+	for _, f := range tris {
+		fMin, fMax := f.STBounds()
+		r0 := clampIndex((float64(fMin[1])-dt-l.min[1])/l.size[1], l.rows)
+		r1 := clampIndex((float64(fMax[1])+dt-l.min[1])/l.size[1], l.rows)
+		for r := r0; r <= r1; r++ {
+			t0 := l.min[1] + float64(r)*l.size[1]
+			s0, s1, ok := slabExtent(f, t0-dt, t0+l.size[1]+dt)
+			if !ok {
+				continue
+			}
+			c0 := clampIndex((s0-ds-l.min[0])/l.size[0], l.cols)
+			c1 := clampIndex((s1+ds-l.min[0])/l.size[0], l.cols)
+			for c := c0; c <= c1; c++ {
+				l.cells[r*l.cols+c] = append(l.cells[r*l.cols+c], f)
+			}
+		}
+	}
+	return l
+}
+
+// slabExtent returns the range of S covered by the part of the triangle f
+// between t0 and t1, and whether the triangle reaches into that slab at all.
+func slabExtent(f *GluFace, t0, t1 float64) (s0, s1 float64, ok bool) {
+	s0, s1 = math.Inf(1), math.Inf(-1)
+	for e := range f.Edges() {
+		var (
+			as, at = float64(e.Org.S), float64(e.Org.T)
+			bs, bt = float64(e.Dst().S), float64(e.Dst().T)
+		)
+		if t0 <= at && at <= t1 {
+			s0, s1 = math.Min(s0, as), math.Max(s1, as)
+		}
+		// Where the edge crosses either side of the slab.
+		for _, t := range [2]float64{t0, t1} {
+			if (at < t) != (bt < t) {
+				s := as + (bs-as)*(t-at)/(bt-at)
+				s0, s1 = math.Min(s0, s), math.Max(s1, s)
+			}
+		}
+	}
+	return s0, s1, s0 <= s1
+}
+
+// cell returns the column and row of the grid cell containing the point,
+// clamped to the grid.
+func (l *Locator) cell(s, t float64) (col, row int) {
+	return clampIndex((s-l.min[0])/l.size[0], l.cols), clampIndex((t-l.min[1])/l.size[1], l.rows)
+}
+
+// clampIndex returns the index of the cell containing x, a position measured
+// in cells, clamped to [0, n).
+func clampIndex(x float64, n int) int {
+	i := int(math.Floor(x))
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// orient returns twice the signed area of the triangle a, b, p; it is
+// positive if p lies to the left of the directed line from a to b.
+func orient(a, b *GluVertex, s, t float64) float64 {
+	var (
+		as, at = float64(a.S), float64(a.T)
+		bs, bt = float64(b.S), float64(b.T)
+	)
+	return (bs-as)*(t-at) - (bt-at)*(s-as)
+}
+
+// barycentric returns the barycentric coordinates of the point with respect
+// to the vertices of the triangle f (in the order AnEdge.Org,
+// AnEdge.LNext.Org, AnEdge.LNext.LNext.Org) and whether the point lies inside
+// or on the boundary of the triangle.
+func barycentric(f *GluFace, s, t float64) (b [3]float32, ok bool) {
+	var (
+		e0 = f.AnEdge
+		e1 = e0.LNext
+		e2 = e1.LNext
+		// Each weight is the area of the sub-triangle opposite its vertex.
+		w0 = orient(e1.Org, e2.Org, s, t)
+		w1 = orient(e2.Org, e0.Org, s, t)
+		w2 = orient(e0.Org, e1.Org, s, t)
+	)
+	if w0 < 0 || w1 < 0 || w2 < 0 {
+		return b, false
+	}
+	sum := w0 + w1 + w2
+	if sum == 0 {
+		return b, false
+	}
+	return [3]float32{float32(w0 / sum), float32(w1 / sum), float32(w2 / sum)}, true
+}
+
+// Locate returns the inside triangle of the mesh which contains the point
+// (s, t) of the S/T plane, along with the barycentric coordinates of the
+// point with respect to the triangle's vertices, in the order AnEdge.Org,
+// AnEdge.LNext.Org and AnEdge.LNext.LNext.Org. Points on an edge shared by
+// two triangles are reported in either one. If no inside triangle contains
+// the point, f is nil.
+func (l *Locator) Locate(s, t float32) (f *GluFace, b [3]float32) {
+	if l.cells == nil {
+		return nil, b
+	}
+	ps, pt := float64(s), float64(t)
+	if !(l.min[0] <= ps && ps <= l.max[0] && l.min[1] <= pt && pt <= l.max[1]) {
+		return nil, b
+	}
+	col, row := l.cell(ps, pt)
+	cell := l.cells[row*l.cols+col]
+	if len(cell) == 0 {
+		return nil, b
+	}
+
+	// Walk towards the point: while it lies to the right of one of the
+	// current triangle's edges, cross that edge.
+	f = cell[0]
+walk:
+	for step := 0; step < l.maxSteps; step++ {
+		for e := range f.Edges() {
+			if orient(e.Org, e.Dst(), ps, pt) >= 0 {
+				continue
+			}
+			next := e.RFace()
+			if next == nil || !next.Inside || !isTriangle(next) {
+				// The walk left the inside region, which need not be
+				// convex; fall back to searching the cell.
+				break walk
+			}
+			f = next
+			continue walk
+		}
+		if b, ok := barycentric(f, ps, pt); ok {
+			return f, b
+		}
+		break
+	}
+
+	// Any triangle containing the point overlaps its cell, so searching the
+	// cell finds it when the walk does not.
+	for _, f := range cell {
+		if b, ok := barycentric(f, ps, pt); ok {
+			return f, b
+		}
+	}
+	return nil, b
+}
+
+// Contains tells whether the point (s, t) of the S/T plane lies inside (or on
+// the boundary of) one of the inside triangles of the mesh.
+func (l *Locator) Contains(s, t float32) bool {
+	f, _ := l.Locate(s, t)
+	return f != nil
+}
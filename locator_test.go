@@ -0,0 +1,142 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+// checkLocate checks that Locate finds a triangle containing (s, t) exactly
+// when want is set, and that the barycentric coordinates it returns
+// reconstruct the point.
+func checkLocate(t *testing.T, name string, l *Locator, s, t0 float32, want bool) {
+	t.Helper()
+	f, b := l.Locate(s, t0)
+	if got := l.Contains(s, t0); got != (f != nil) {
+		t.Errorf("%s: (%g, %g): Contains = %t, but Locate returned %v", name, s, t0, got, f)
+	}
+	if (f != nil) != want {
+		t.Errorf("%s: (%g, %g): located %t, want %t", name, s, t0, f != nil, want)
+		return
+	}
+	if f == nil {
+		return
+	}
+	if !f.Inside || !isTriangle(f) {
+		t.Errorf("%s: (%g, %g): located a face which is not an inside triangle", name, s, t0)
+	}
+	var (
+		v      = [3]*GluVertex{f.AnEdge.Org, f.AnEdge.LNext.Org, f.AnEdge.LNext.LNext.Org}
+		ps, pt float64
+		sum    float64
+	)
+	for i, w := range b {
+		if w < 0 {
+			t.Errorf("%s: (%g, %g): negative barycentric coordinate %v", name, s, t0, b)
+		}
+		ps += float64(w) * float64(v[i].S)
+		pt += float64(w) * float64(v[i].T)
+		sum += float64(w)
+	}
+	if math.Abs(sum-1) > 1e-6 || math.Abs(ps-float64(s)) > 1e-5 || math.Abs(pt-float64(t0)) > 1e-5 {
+		t.Errorf("%s: (%g, %g): barycentric coordinates %v give (%g, %g), sum %g", name, s, t0, b, ps, pt, sum)
+	}
+}
+
+func TestLocateGrid(t *testing.T) {
+	// The lattice's points lie inside triangles, on the edges between them
+	// (including the diagonals) and on vertices.
+	l := NewLocator(gridMesh(4))
+	for i := 0; i <= 16; i++ {
+		for j := 0; j <= 16; j++ {
+			checkLocate(t, "grid", l, float32(i)/4, float32(j)/4, true)
+		}
+	}
+
+	// Points beyond the grid's bounds, including its bounding box's corners
+	// pushed outward.
+	for _, p := range [][2]float32{
+		{-0.1, 2}, {4.1, 2}, {2, -1e-3}, {2, 4.001},
+		{-1, -1}, {5, 5}, {-1e30, 0}, {1e30, 1e30},
+	} {
+		checkLocate(t, "outside the grid", l, p[0], p[1], false)
+	}
+}
+
+func TestLocateNonConvex(t *testing.T) {
+	// A U shape: the 3×3 grid without the middle column's upper two squares,
+	// leaving a gap between its two arms.
+	g := holedGridMesh(3, map[[2]int]bool{{1, 1}: true, {1, 2}: true})
+	inGap := func(s, t float32) bool { return s > 1 && s < 2 && t > 1 }
+
+	// Start every walk in the upper triangle at the top of the right arm, so
+	// that walks towards the left arm run into the gap.
+	var start *GluFace
+	for f := range g.Faces() {
+		if s, t := f.Centroid(); f.Inside && s > 2 && s < 2.5 && t > 2.5 {
+			start = f
+		}
+	}
+	l := NewLocator(g)
+	for i := range l.cells {
+		l.cells[i] = append([]*GluFace{start}, l.cells[i]...)
+	}
+
+	for i := 0; i <= 12; i++ {
+		for j := 0; j <= 12; j++ {
+			s, t0 := float32(i)/4, float32(j)/4
+			checkLocate(t, "U shape", l, s, t0, !inGap(s, t0))
+		}
+	}
+	// Far from the start, at the bottom of the left arm.
+	checkLocate(t, "U shape", l, 0.1, 0.1, true)
+}
+
+func TestLocateEmpty(t *testing.T) {
+	for name, g := range map[string]*GluMesh{
+		"empty mesh":        NewGluMesh(),
+		"no inside faces":   newTestMesh([][2]float32{{0, 0}, {1, 0}, {0, 1}}, nil),
+		"degenerate bounds": newTestMesh([][2]float32{{0, 0}, {1, 0}, {2, 0}}, [][3]int{{0, 1, 2}}),
+	} {
+		l := NewLocator(g)
+		checkLocate(t, name, l, 0.5, 0, false)
+		checkLocate(t, name, l, 0, 0.5, false)
+	}
+}
+
+func TestLocatorFan(t *testing.T) {
+	// A fan of thin triangles around the origin. Each of them spans about
+	// sqrt(n) cells along its length, but its bounding box covers up to n of
+	// them; storing it in all of those would take memory quadratic in n.
+	const n = 8192
+	var (
+		points = [][2]float32{{0, 0}}
+		tris   [][3]int
+	)
+	for i := 0; i < n; i++ {
+		a := 2 * math.Pi * float64(i) / n
+		points = append(points, [2]float32{float32(math.Cos(a)), float32(math.Sin(a))})
+		tris = append(tris, [3]int{0, i + 1, (i+1)%n + 1})
+	}
+	l := NewLocator(newTestMesh(points, tris))
+
+	entries := 0
+	for _, c := range l.cells {
+		entries += len(c)
+	}
+	if entries > n*int(math.Sqrt(n)) {
+		t.Errorf("got %d cell entries for %d triangles", entries, n)
+	}
+
+	for i := 0; i < 100; i++ {
+		var (
+			a = 2 * math.Pi * float64(i) / 100
+			r = float64(i%10) / 10
+		)
+		checkLocate(t, "fan", l, float32(r*math.Cos(a)), float32(r*math.Sin(a)), true)
+	}
+	checkLocate(t, "fan", l, 0.9, 0.9, false)
+}
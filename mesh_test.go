@@ -0,0 +1,197 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import "testing"
+
+// newTestMesh builds a mesh by hand from points in the S/T plane and
+// counter-clockwise triangles indexing them, which become the inside faces.
+// Each loop of boundary edges gets an outside face of its own, so the mesh is
+// closed. The triangles must form a manifold: every edge is shared by at most
+// two triangles, and at most one boundary loop passes through each point.
+func newTestMesh(points [][2]float32, tris [][3]int) *GluMesh {
+	polys := make([][]int, len(tris))
+	for i := range tris {
+		polys[i] = tris[i][:]
+	}
+	return newPolygonMesh(points, polys)
+}
+
+// newPolygonMesh is like newTestMesh, with counter-clockwise polygons of any
+// size instead of triangles.
+func newPolygonMesh(points [][2]float32, polys [][]int) *GluMesh {
+	g := NewGluMesh()
+
+	verts := make([]*GluVertex, len(points))
+	for i, p := range points {
+		v := NewGluVertex(g.VHead, g.VHead.Prev)
+		v.Prev.Next = v
+		g.VHead.Prev = v
+		v.S, v.T = p[0], p[1]
+		v.Coords = [3]float32{p[0], p[1], 0}
+		verts[i] = v
+	}
+	newFace := func(inside bool) *GluFace {
+		f := NewGluFace(g.FHead, g.FHead.Prev)
+		f.Prev.Next = f
+		g.FHead.Prev = f
+		f.Inside = inside
+		return f
+	}
+
+	// Create each edge as a pair of half-edges the first time a polygon
+	// uses it, in either direction.
+	var (
+		edges []*GluHalfEdge
+		half  = make(map[[2]int]*GluHalfEdge)
+	)
+	halfEdge := func(a, b int) *GluHalfEdge {
+		if e, ok := half[[2]int{a, b}]; ok {
+			return e
+		}
+		e, sym := NewGluHalfEdge(nil), NewGluHalfEdge(nil)
+		e.Sym, sym.Sym = sym, e
+		e.Org, sym.Org = verts[a], verts[b]
+		half[[2]int{a, b}], half[[2]int{b, a}] = e, sym
+		edges = append(edges, e)
+		return e
+	}
+	for _, poly := range polys {
+		f := newFace(true)
+		for i := range poly {
+			n := len(poly)
+			e := halfEdge(poly[i], poly[(i+1)%n])
+			e.LNext = halfEdge(poly[(i+1)%n], poly[(i+2)%n])
+			e.LFace = f
+			f.AnEdge = e
+		}
+	}
+
+	// The half-edges without a face form the boundary loops, traversed with
+	// the outside on their left.
+	outer := make(map[*GluVertex]*GluHalfEdge)
+	for _, e := range edges {
+		for _, h := range [2]*GluHalfEdge{e, e.Sym} {
+			if h.LFace == nil {
+				outer[h.Org] = h
+			}
+		}
+	}
+	for _, e := range edges {
+		for _, h := range [2]*GluHalfEdge{e, e.Sym} {
+			if h.LFace != nil {
+				continue
+			}
+			f := newFace(false)
+			f.AnEdge = h
+			for l := h; l.LFace == nil; l = l.LNext {
+				l.LFace = f
+				l.LNext = outer[l.Dst()]
+			}
+		}
+	}
+
+	// Derive ONext from LNext, using e.ONext.Sym.LNext == e.
+	for _, e := range edges {
+		for _, h := range [2]*GluHalfEdge{e, e.Sym} {
+			h.LNext.ONext = h.Sym
+			h.Org.AnEdge = h
+		}
+	}
+
+	prev := g.EHead
+	for _, e := range edges {
+		prev.Next = e
+		e.Sym.Next = prev.Sym
+		prev = e
+	}
+	prev.Next = g.EHead
+	g.EHeadSym.Next = prev.Sym
+	return g
+}
+
+// removeOutsideFaces removes the outside faces from the mesh, leaving the
+// half-edges around them with a nil LFace, as in a tessellation result.
+func removeOutsideFaces(g *GluMesh) {
+	for f := range g.Faces() {
+		if f.Inside {
+			continue
+		}
+		for e := range f.Edges() {
+			e.LFace = nil
+		}
+		f.Prev.Next, f.Next.Prev = f.Next, f.Prev
+	}
+}
+
+// checkMesh fails the test if the mesh connectivity is inconsistent. It
+// mirrors GluMesh.Check, which does nothing unless DEBUG is set.
+func checkMesh(t *testing.T, g *GluMesh) {
+	t.Helper()
+	for f := range g.Faces() {
+		for e := range f.Edges() {
+			if e.Sym.Sym != e || e.LNext.ONext.Sym != e || e.ONext.Sym.LNext != e || e.LFace != f {
+				t.Fatalf("inconsistent half-edge around face %p", f)
+			}
+		}
+	}
+	for v := range g.Vertices() {
+		for e := range v.Edges() {
+			if e.Org != v {
+				t.Fatalf("inconsistent half-edge around vertex %p", v)
+			}
+		}
+	}
+	for e := range g.Edges() {
+		if e.Sym.Next.Sym.Next != e {
+			t.Fatalf("inconsistent edge list at %p", e)
+		}
+	}
+}
+
+// gridMesh returns a mesh of n×n unit squares, each split into two triangles
+// by the diagonal from its lower left to its upper right corner.
+func gridMesh(n int) *GluMesh {
+	return holedGridMesh(n, nil)
+}
+
+// holedGridMesh returns a grid mesh like gridMesh, without the squares whose
+// lower left corner is in holes.
+func holedGridMesh(n int, holes map[[2]int]bool) *GluMesh {
+	var (
+		points [][2]float32
+		tris   [][3]int
+	)
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			points = append(points, [2]float32{float32(x), float32(y)})
+		}
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if holes[[2]int{x, y}] {
+				continue
+			}
+			a := y*(n+1) + x
+			b, c, d := a+1, a+n+2, a+n+1
+			tris = append(tris, [3]int{a, b, c}, [3]int{a, c, d})
+		}
+	}
+	return newTestMesh(points, tris)
+}
+
+func TestNewTestMesh(t *testing.T) {
+	g := gridMesh(3)
+	checkMesh(t, g)
+	if !g.closed() {
+		t.Fatal("mesh is not closed")
+	}
+	if got := g.EulerCharacteristic(); got != 2 {
+		t.Errorf("EulerCharacteristic() = %d, want 2", got)
+	}
+	if got := g.BoundaryLoops(); got != 1 {
+		t.Errorf("BoundaryLoops() = %d, want 1", got)
+	}
+}
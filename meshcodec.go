@@ -0,0 +1,438 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The binary mesh format starts with meshMagic, followed by a version byte and
+// a flags byte. Then come the number of vertices, faces and edges and the
+// elements themselves, in list order:
+//
+//	vertex:    Coords, S, T (float32 each), AnEdge, [Data]
+//	face:      Inside (byte), AnEdge, [Data]
+//	half-edge: Org, LFace, ONext, LNext, winding (signed)
+//
+// Unless noted otherwise, integers are unsigned varints and floats are stored
+// little-endian. Half-edges are numbered in pairs: the edge in the edge list is
+// 2*i and its Sym is 2*i+1. LFace is the face index plus one, zero meaning no
+// face. Data, present only if meshFlagData is set, is a varint length followed
+// by that many bytes.
+const (
+	meshMagic    = "tess"
+	meshVersion  = 1
+	meshFlagData = 1 << 0
+)
+
+// ErrMeshFormat is returned when decoding data which is not an encoded mesh,
+// or an encoded mesh of an unsupported version.
+var ErrMeshFormat = errors.New("tess: not an encoded mesh of a supported version")
+
+// DataEncoder encodes the client Data of a vertex or face (which may be nil)
+// for GluMesh.Encode.
+type DataEncoder func(data interface{}) ([]byte, error)
+
+// DataDecoder decodes client Data encoded by a DataEncoder for DecodeGluMesh.
+type DataDecoder func(b []byte) (interface{}, error)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The client
+// Data of vertices and faces is not encoded; use Encode for that.
+func (g *GluMesh) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := g.Encode(&buf, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// mesh is replaced by the decoded one; client Data, if any was encoded, is
+// discarded.
+func (g *GluMesh) UnmarshalBinary(data []byte) error {
+	m, err := DecodeGluMesh(bytes.NewReader(data), nil)
+	if err != nil {
+		return err
+	}
+	*g = *m
+	return nil
+}
+
+// Encode writes the mesh to w in a compact, versioned binary format. If enc
+// is not nil, it is used to encode the client Data of each vertex and face.
+func (g *GluMesh) Encode(w io.Writer, enc DataEncoder) error {
+	var (
+		x     = newMeshIndex(g)
+		bw    = bufio.NewWriter(w)
+		flags byte
+		tmp   [binary.MaxVarintLen64]byte
+	)
+	if enc != nil {
+		flags |= meshFlagData
+	}
+	putUvarint := func(v uint64) {
+		bw.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+	}
+	putFloat := func(f float32) {
+		binary.LittleEndian.PutUint32(tmp[:], math.Float32bits(f))
+		bw.Write(tmp[:4])
+	}
+	putData := func(data interface{}) error {
+		if enc == nil {
+			return nil
+		}
+		b, err := enc(data)
+		if err != nil {
+			return err
+		}
+		putUvarint(uint64(len(b)))
+		bw.Write(b)
+		return nil
+	}
+	edge := func(e *GluHalfEdge) (uint64, error) {
+		i, ok := x.edges[e]
+		if !ok {
+			return 0, errors.New("tess: cannot encode mesh with a dangling edge pointer")
+		}
+		return uint64(i), nil
+	}
+
+	bw.WriteString(meshMagic)
+	bw.WriteByte(meshVersion)
+	bw.WriteByte(flags)
+	putUvarint(uint64(len(x.verts)))
+	putUvarint(uint64(len(x.faces)))
+	putUvarint(uint64(len(x.edges) / 2))
+
+	for v := range g.Vertices() {
+		for _, c := range v.Coords {
+			putFloat(c)
+		}
+		putFloat(v.S)
+		putFloat(v.T)
+		i, err := edge(v.AnEdge)
+		if err != nil {
+			return err
+		}
+		putUvarint(i)
+		if err := putData(v.Data); err != nil {
+			return err
+		}
+	}
+
+	for f := range g.Faces() {
+		var inside byte
+		if f.Inside {
+			inside = 1
+		}
+		bw.WriteByte(inside)
+		i, err := edge(f.AnEdge)
+		if err != nil {
+			return err
+		}
+		putUvarint(i)
+		if err := putData(f.Data); err != nil {
+			return err
+		}
+	}
+
+	for e := range g.Edges() {
+		for _, h := range [2]*GluHalfEdge{e, e.Sym} {
+			org, ok := x.verts[h.Org]
+			if !ok {
+				return errors.New("tess: cannot encode mesh with a dangling vertex pointer")
+			}
+			putUvarint(uint64(org))
+
+			var lFace uint64
+			if h.LFace != nil {
+				i, ok := x.faces[h.LFace]
+				if !ok {
+					return errors.New("tess: cannot encode mesh with a dangling face pointer")
+				}
+				lFace = uint64(i) + 1
+			}
+			putUvarint(lFace)
+
+			for _, n := range [2]*GluHalfEdge{h.ONext, h.LNext} {
+				i, err := edge(n)
+				if err != nil {
+					return err
+				}
+				putUvarint(i)
+			}
+			bw.Write(tmp[:binary.PutVarint(tmp[:], int64(h.winding))])
+		}
+	}
+	return bw.Flush()
+}
+
+// DecodeGluMesh reads a mesh written by GluMesh.Encode from r. If dec is not
+// nil, it is used to decode the client Data of each vertex and face, if any
+// was encoded.
+//
+// The topology of the decoded mesh is validated, and an error is returned if
+// it is inconsistent: besides the pointers of each element, every vertex and
+// face must have a single ring of edges, and every connected piece of a
+// closed mesh must be a topological sphere (see EulerCharacteristic).
+func DecodeGluMesh(r io.Reader, dec DataDecoder) (*GluMesh, error) {
+	br := bufio.NewReader(r)
+
+	var hdr [len(meshMagic) + 2]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if string(hdr[:len(meshMagic)]) != meshMagic || hdr[len(meshMagic)] != meshVersion {
+		return nil, ErrMeshFormat
+	}
+	hasData := hdr[len(meshMagic)+1]&meshFlagData != 0
+
+	var (
+		err    error
+		tmp    [4]byte
+		counts [3]uint64
+	)
+	for i := range counts {
+		if counts[i], err = binary.ReadUvarint(br); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+	nVerts, nFaces, nEdges := counts[0], counts[1], counts[2]
+	if nEdges > math.MaxInt/2 {
+		return nil, errors.New("tess: invalid encoded mesh: too many edges")
+	}
+	nHalf := 2 * nEdges
+
+	readIndex := func(n uint64, what string) (int, error) {
+		i, err := binary.ReadUvarint(br)
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		if i >= n {
+			return 0, fmt.Errorf("tess: invalid encoded mesh: %s index %d out of range", what, i)
+		}
+		return int(i), nil
+	}
+	readFloat := func() (float32, error) {
+		if _, err := io.ReadFull(br, tmp[:]); err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(tmp[:])), nil
+	}
+	readData := func() (interface{}, error) {
+		if !hasData {
+			return nil, nil
+		}
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if n > math.MaxInt64 {
+			return nil, errors.New("tess: invalid encoded mesh: data too long")
+		}
+		// Copy rather than allocate n bytes up front, in case n is bogus.
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, br, int64(n)); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if dec == nil {
+			return nil, nil
+		}
+		return dec(buf.Bytes())
+	}
+
+	// Element slices are grown as elements are read rather than allocated up
+	// front, so that bogus counts fail with an EOF instead of allocating.
+	var (
+		g        = NewGluMesh()
+		verts    []*GluVertex
+		faces    []*GluFace
+		halves   []*GluHalfEdge
+		vAnEdges []int
+		fAnEdges []int
+	)
+
+	for i := uint64(0); i < nVerts; i++ {
+		v := NewGluVertex(g.VHead, g.VHead.Prev)
+		for j := range v.Coords {
+			if v.Coords[j], err = readFloat(); err != nil {
+				return nil, err
+			}
+		}
+		if v.S, err = readFloat(); err != nil {
+			return nil, err
+		}
+		if v.T, err = readFloat(); err != nil {
+			return nil, err
+		}
+		e, err := readIndex(nHalf, "vertex edge")
+		if err != nil {
+			return nil, err
+		}
+		if v.Data, err = readData(); err != nil {
+			return nil, err
+		}
+		v.Prev.Next = v
+		g.VHead.Prev = v
+		verts = append(verts, v)
+		vAnEdges = append(vAnEdges, e)
+	}
+
+	for i := uint64(0); i < nFaces; i++ {
+		f := NewGluFace(g.FHead, g.FHead.Prev)
+		inside, err := br.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		f.Inside = inside != 0
+		e, err := readIndex(nHalf, "face edge")
+		if err != nil {
+			return nil, err
+		}
+		if f.Data, err = readData(); err != nil {
+			return nil, err
+		}
+		f.Prev.Next = f
+		g.FHead.Prev = f
+		faces = append(faces, f)
+		fAnEdges = append(fAnEdges, e)
+	}
+
+	// Half-edges reference each other, so they are all created before their
+	// pointers are filled in.
+	type halfRef struct {
+		org, lFace, oNext, lNext int
+		winding                  int64
+	}
+	var refs []halfRef
+	for i := uint64(0); i < nHalf; i++ {
+		var (
+			ref halfRef
+			err error
+		)
+		if ref.org, err = readIndex(nVerts, "edge origin"); err != nil {
+			return nil, err
+		}
+		if ref.lFace, err = readIndex(nFaces+1, "edge face"); err != nil {
+			return nil, err
+		}
+		if ref.oNext, err = readIndex(nHalf, "edge ONext"); err != nil {
+			return nil, err
+		}
+		if ref.lNext, err = readIndex(nHalf, "edge LNext"); err != nil {
+			return nil, err
+		}
+		if ref.winding, err = binary.ReadVarint(br); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		refs = append(refs, ref)
+		halves = append(halves, NewGluHalfEdge(nil))
+	}
+
+	// Link the edge list, see GluHalfEdge for its layout.
+	ePrev := g.EHead
+	for i := 0; i < len(halves); i += 2 {
+		e, eSym := halves[i], halves[i+1]
+		e.Sym, eSym.Sym = eSym, e
+		ePrev.Next = e
+		eSym.Next = ePrev.Sym
+		ePrev = e
+	}
+	ePrev.Next = g.EHead
+	g.EHeadSym.Next = ePrev.Sym
+
+	for i, ref := range refs {
+		e := halves[i]
+		e.Org = verts[ref.org]
+		if ref.lFace > 0 {
+			e.LFace = faces[ref.lFace-1]
+		}
+		e.ONext = halves[ref.oNext]
+		e.LNext = halves[ref.lNext]
+		e.winding = int(ref.winding)
+	}
+	for i, v := range verts {
+		v.AnEdge = halves[vAnEdges[i]]
+	}
+	for i, f := range faces {
+		f.AnEdge = halves[fAnEdges[i]]
+	}
+
+	// Validate the topology, so that corrupt input cannot make traversals of
+	// the mesh loop forever or wander off to the wrong elements.
+	for _, e := range halves {
+		switch {
+		case e.LNext.ONext.Sym != e || e.ONext.Sym.LNext != e:
+			return nil, errors.New("tess: invalid encoded mesh: inconsistent ONext/LNext pointers")
+		case e.ONext.Org != e.Org:
+			return nil, errors.New("tess: invalid encoded mesh: inconsistent edge origins")
+		case e.LNext.LFace != e.LFace:
+			return nil, errors.New("tess: invalid encoded mesh: inconsistent edge faces")
+		}
+	}
+	for _, v := range verts {
+		if v.AnEdge.Org != v {
+			return nil, errors.New("tess: invalid encoded mesh: vertex edge does not originate at it")
+		}
+	}
+	for _, f := range faces {
+		if f.AnEdge.LFace != f {
+			return nil, errors.New("tess: invalid encoded mesh: face edge does not border it")
+		}
+	}
+
+	// The checks above are all local. Each vertex and face has at least one
+	// ring of edges (the one through its AnEdge), so counting the rings
+	// catches vertices and faces split into several.
+	rings := func(next func(e *GluHalfEdge) *GluHalfEdge) (starts []*GluHalfEdge) {
+		seen := make(map[*GluHalfEdge]bool, len(halves))
+		for _, e := range halves {
+			if seen[e] {
+				continue
+			}
+			for h := e; !seen[h]; h = next(h) {
+				seen[h] = true
+			}
+			starts = append(starts, e)
+		}
+		return
+	}
+	if len(rings(func(e *GluHalfEdge) *GluHalfEdge { return e.ONext })) != len(verts) {
+		return nil, errors.New("tess: invalid encoded mesh: vertex with several edge rings")
+	}
+	nLoops := 0
+	for _, e := range rings(func(e *GluHalfEdge) *GluHalfEdge { return e.LNext }) {
+		if e.LFace != nil {
+			nLoops++
+		}
+	}
+	if nLoops != len(faces) {
+		return nil, errors.New("tess: invalid encoded mesh: face with several edge loops")
+	}
+
+	// No connected closed surface has an Euler characteristic above two, so
+	// the sum over all pieces is 2*components only if each piece is a sphere.
+	if g.closed() && g.EulerCharacteristic() != 2*g.components() {
+		return nil, errors.New("tess: invalid encoded mesh: surface is not a sphere")
+	}
+
+	g.Check()
+	return g, nil
+}
+
+// unexpectedEOF turns io.EOF into io.ErrUnexpectedEOF, as running out of data
+// in the middle of a mesh is always unexpected.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// The test Data is an int, encoded in decimal.
+func encodeTestData(data interface{}) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+	return []byte(strconv.Itoa(data.(int))), nil
+}
+
+func decodeTestData(b []byte) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return strconv.Atoi(string(b))
+}
+
+// dataMesh returns a grid mesh whose vertices and inside faces carry their
+// index as Data.
+func dataMesh() *GluMesh {
+	g := gridMesh(2)
+	i := 0
+	for v := range g.Vertices() {
+		v.Data = i
+		i++
+	}
+	for f := range g.Faces() {
+		if f.Inside {
+			f.Data = i
+		}
+		i++
+	}
+	return g
+}
+
+func encodeMesh(t testing.TB, g *GluMesh, enc DataEncoder) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := g.Encode(&buf, enc); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	var (
+		g    = dataMesh()
+		data = encodeMesh(t, g, encodeTestData)
+	)
+	m, err := DecodeGluMesh(bytes.NewReader(data), decodeTestData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMesh(t, m)
+
+	// The encoding follows list order, so a faithful decode encodes to the
+	// same bytes.
+	if !bytes.Equal(encodeMesh(t, m, encodeTestData), data) {
+		t.Error("re-encoded mesh differs")
+	}
+
+	var gv, mv []*GluVertex
+	for v := range g.Vertices() {
+		gv = append(gv, v)
+	}
+	for v := range m.Vertices() {
+		mv = append(mv, v)
+	}
+	if len(gv) != len(mv) {
+		t.Fatalf("got %d vertices, want %d", len(mv), len(gv))
+	}
+	for i := range gv {
+		if mv[i].Coords != gv[i].Coords || mv[i].S != gv[i].S || mv[i].T != gv[i].T || mv[i].Data != gv[i].Data {
+			t.Errorf("vertex %d: got %+v, want %+v", i, mv[i], gv[i])
+		}
+	}
+
+	var gf, mf []*GluFace
+	for f := range g.Faces() {
+		gf = append(gf, f)
+	}
+	for f := range m.Faces() {
+		mf = append(mf, f)
+	}
+	if len(gf) != len(mf) {
+		t.Fatalf("got %d faces, want %d", len(mf), len(gf))
+	}
+	for i := range gf {
+		if mf[i].Inside != gf[i].Inside || mf[i].Data != gf[i].Data {
+			t.Errorf("face %d: got Inside=%v Data=%v, want Inside=%v Data=%v", i, mf[i].Inside, mf[i].Data, gf[i].Inside, gf[i].Data)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	g := dataMesh()
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewGluMesh()
+	if err := m.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	checkMesh(t, m)
+	for v := range m.Vertices() {
+		if v.Data != nil {
+			t.Fatalf("vertex Data = %v, want nil", v.Data)
+		}
+	}
+	if got := m.EulerCharacteristic(); got != 2 {
+		t.Errorf("EulerCharacteristic() = %d, want 2", got)
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	data := encodeMesh(t, dataMesh(), encodeTestData)
+	for n := 0; n < len(data); n++ {
+		_, err := DecodeGluMesh(bytes.NewReader(data[:n]), decodeTestData)
+		if err == nil {
+			t.Fatalf("decoding %d of %d bytes succeeded", n, len(data))
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("decoding %d of %d bytes: got %v, want %v", n, len(data), err, io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+func TestDecodeBadHeader(t *testing.T) {
+	data := encodeMesh(t, dataMesh(), nil)
+	for _, i := range []int{0, len(meshMagic)} {
+		bad := append([]byte(nil), data...)
+		bad[i]++
+		if _, err := DecodeGluMesh(bytes.NewReader(bad), nil); err != ErrMeshFormat {
+			t.Errorf("byte %d changed: got %v, want %v", i, err, ErrMeshFormat)
+		}
+	}
+}
+
+func TestDecodeTorus(t *testing.T) {
+	// A 3×3 grid whose opposite sides are glued together is a torus, which
+	// passes all local checks but has an Euler characteristic of zero.
+	var tris [][3]int
+	points := make([][2]float32, 9)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			points[y*3+x] = [2]float32{float32(x), float32(y)}
+			var (
+				a = y*3 + x
+				b = y*3 + (x+1)%3
+				c = (y+1)%3*3 + (x+1)%3
+				d = (y+1)%3*3 + x
+			)
+			tris = append(tris, [3]int{a, b, c}, [3]int{a, c, d})
+		}
+	}
+	g := newTestMesh(points, tris)
+	checkMesh(t, g)
+	if got := g.EulerCharacteristic(); got != 0 {
+		t.Fatalf("EulerCharacteristic() = %d, want 0", got)
+	}
+
+	data := encodeMesh(t, g, nil)
+	if _, err := DecodeGluMesh(bytes.NewReader(data), nil); err == nil {
+		t.Error("decoding a torus succeeded")
+	}
+}
+
+func TestDecodeSplitVertex(t *testing.T) {
+	// Two triangles made to touch at a single point, by merging a vertex of
+	// one into a vertex of the other: the merged vertex has two rings of
+	// edges, one per triangle.
+	var (
+		g = newTestMesh(
+			[][2]float32{{0, 0}, {1, 0}, {1, 1}, {1, 1}, {2, 1}, {2, 2}},
+			[][3]int{{0, 1, 2}, {3, 4, 5}},
+		)
+		verts []*GluVertex
+	)
+	for v := range g.Vertices() {
+		verts = append(verts, v)
+	}
+	for e := range verts[3].Edges() {
+		e.Org = verts[2]
+	}
+	verts[3].Prev.Next = verts[3].Next
+	verts[3].Next.Prev = verts[3].Prev
+
+	data := encodeMesh(t, g, nil)
+	if _, err := DecodeGluMesh(bytes.NewReader(data), nil); err == nil {
+		t.Error("decoding a split vertex succeeded")
+	}
+}
+
+func FuzzDecodeGluMesh(f *testing.F) {
+	f.Add(encodeMesh(f, dataMesh(), nil))
+	f.Add(encodeMesh(f, dataMesh(), encodeTestData))
+	f.Add(encodeMesh(f, gridMesh(1), nil))
+	f.Add(encodeMesh(f, NewGluMesh(), nil))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g, err := DecodeGluMesh(bytes.NewReader(data), func(b []byte) (interface{}, error) {
+			return string(b), nil
+		})
+		if err != nil {
+			return
+		}
+		checkMesh(t, g)
+		if _, err := g.MarshalBinary(); err != nil {
+			t.Fatalf("cannot encode decoded mesh: %v", err)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import "iter"
+
+// Faces returns an iterator over the faces of the mesh, in list order. The
+// dummy header is not visited.
+func (g *GluMesh) Faces() iter.Seq[*GluFace] {
+	return func(yield func(*GluFace) bool) {
+		for f := g.FHead.Next; f != g.FHead; {
+			next := f.Next
+			if !yield(f) {
+				return
+			}
+			f = next
+		}
+	}
+}
+
+// Vertices returns an iterator over the vertices of the mesh, in list order.
+// The dummy header is not visited.
+func (g *GluMesh) Vertices() iter.Seq[*GluVertex] {
+	return func(yield func(*GluVertex) bool) {
+		for v := g.VHead.Next; v != g.VHead; {
+			next := v.Next
+			if !yield(v) {
+				return
+			}
+			v = next
+		}
+	}
+}
+
+// Edges returns an iterator over the edges of the mesh, in list order. Each
+// edge is visited once, as the one of its two half-edges that is in the edge
+// list (use Sym for the other). The dummy header is not visited.
+func (g *GluMesh) Edges() iter.Seq[*GluHalfEdge] {
+	return func(yield func(*GluHalfEdge) bool) {
+		for e := g.EHead.Next; e != g.EHead; {
+			next := e.Next
+			if !yield(e) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Edges returns an iterator over the half-edges around the face, that is the
+// half-edges with this face on their left, in counter-clockwise order (by
+// following LNext) starting at AnEdge.
+func (f *GluFace) Edges() iter.Seq[*GluHalfEdge] {
+	return func(yield func(*GluHalfEdge) bool) {
+		e := f.AnEdge
+		for {
+			if !yield(e) {
+				return
+			}
+			e = e.LNext
+			if e == f.AnEdge {
+				return
+			}
+		}
+	}
+}
+
+// Neighbors returns an iterator over the faces adjacent to this one, that is
+// the right faces of its edges, in the order of Edges. Each neighbor is
+// visited once, even if it shares several edges with this face.
+func (f *GluFace) Neighbors() iter.Seq[*GluFace] {
+	return func(yield func(*GluFace) bool) {
+		var seen []*GluFace
+	edges:
+		for e := range f.Edges() {
+			r := e.RFace()
+			for _, s := range seen {
+				if s == r {
+					continue edges
+				}
+			}
+			seen = append(seen, r)
+			if !yield(r) {
+				return
+			}
+		}
+	}
+}
+
+// Edges returns an iterator over the half-edges leaving the vertex, that is
+// the half-edges with this vertex as their origin, in counter-clockwise order
+// (by following ONext) starting at AnEdge.
+func (v *GluVertex) Edges() iter.Seq[*GluHalfEdge] {
+	return func(yield func(*GluHalfEdge) bool) {
+		e := v.AnEdge
+		for {
+			if !yield(e) {
+				return
+			}
+			e = e.ONext
+			if e == v.AnEdge {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import "math"
+
+// faceArea returns the signed area of the face's edge loop in the S/T plane,
+// which is positive for counter-clockwise loops.
+func faceArea(f *GluFace) float64 {
+	var area float64
+	for e := range f.Edges() {
+		area += (float64(e.Org.S) - float64(e.Dst().S)) * (float64(e.Org.T) + float64(e.Dst().T))
+	}
+	return area / 2
+}
+
+// Area returns the signed area of the face's edge loop in the S/T plane. It
+// is positive for counter-clockwise loops, such as the faces produced for the
+// polygon interior, and negative for clockwise ones.
+func (f *GluFace) Area() float32 {
+	return float32(faceArea(f))
+}
+
+// Centroid returns the centroid of the region enclosed by the face's edge
+// loop in the S/T plane. If the loop encloses no area, the average of its
+// vertices is returned instead.
+func (f *GluFace) Centroid() (s, t float32) {
+	var (
+		area, cs, ct float64
+		ss, st       float64
+		n            int
+	)
+	for e := range f.Edges() {
+		var (
+			s0, t0 = float64(e.Org.S), float64(e.Org.T)
+			s1, t1 = float64(e.Dst().S), float64(e.Dst().T)
+			cross  = s0*t1 - s1*t0
+		)
+		area += cross
+		cs += (s0 + s1) * cross
+		ct += (t0 + t1) * cross
+		ss += s0
+		st += t0
+		n++
+	}
+	if area == 0 {
+		return float32(ss / float64(n)), float32(st / float64(n))
+	}
+	return float32(cs / (3 * area)), float32(ct / (3 * area))
+}
+
+// Bounds returns the axis-aligned bounding box of the Coords of the face's
+// vertices.
+func (f *GluFace) Bounds() (min, max [3]float32) {
+	min, max = emptyBounds()
+	for e := range f.Edges() {
+		min, max = extendBounds(min, max, e.Org)
+	}
+	return
+}
+
+// STBounds returns the axis-aligned bounding box of the S/T projection of the
+// face's vertices.
+func (f *GluFace) STBounds() (min, max [2]float32) {
+	min, max = emptySTBounds()
+	for e := range f.Edges() {
+		min, max = extendSTBounds(min, max, e.Org)
+	}
+	return
+}
+
+// Bounds returns the axis-aligned bounding box of the Coords of the mesh's
+// vertices. For a mesh without vertices, min is +Inf and max is -Inf.
+func (g *GluMesh) Bounds() (min, max [3]float32) {
+	min, max = emptyBounds()
+	for v := range g.Vertices() {
+		min, max = extendBounds(min, max, v)
+	}
+	return
+}
+
+// STBounds returns the axis-aligned bounding box of the S/T projection of the
+// mesh's vertices. For a mesh without vertices, min is +Inf and max is -Inf.
+func (g *GluMesh) STBounds() (min, max [2]float32) {
+	min, max = emptySTBounds()
+	for v := range g.Vertices() {
+		min, max = extendSTBounds(min, max, v)
+	}
+	return
+}
+
+func emptyBounds() (min, max [3]float32) {
+	inf := float32(math.Inf(1))
+	return [3]float32{inf, inf, inf}, [3]float32{-inf, -inf, -inf}
+}
+
+func extendBounds(min, max [3]float32, v *GluVertex) ([3]float32, [3]float32) {
+	for i, c := range v.Coords {
+		if c < min[i] {
+			min[i] = c
+		}
+		if c > max[i] {
+			max[i] = c
+		}
+	}
+	return min, max
+}
+
+func emptySTBounds() (min, max [2]float32) {
+	inf := float32(math.Inf(1))
+	return [2]float32{inf, inf}, [2]float32{-inf, -inf}
+}
+
+func extendSTBounds(min, max [2]float32, v *GluVertex) ([2]float32, [2]float32) {
+	for i, c := range [2]float32{v.S, v.T} {
+		if c < min[i] {
+			min[i] = c
+		}
+		if c > max[i] {
+			max[i] = c
+		}
+	}
+	return min, max
+}
+
+// Valence returns the number of edges leaving the vertex.
+func (v *GluVertex) Valence() int {
+	n := 0
+	for range v.Edges() {
+		n++
+	}
+	return n
+}
+
+// isBoundary tells whether the half-edge has an inside face on its left and
+// an outside (or no) face on its right.
+func isBoundary(e *GluHalfEdge) bool {
+	inside := func(f *GluFace) bool { return f != nil && f.Inside }
+	return inside(e.LFace) && !inside(e.RFace())
+}
+
+// BoundaryLoops returns the number of closed loops formed by the boundary
+// edges of the mesh, the edges separating an inside face from an outside one.
+// For instance, a square with one hole has two boundary loops.
+func (g *GluMesh) BoundaryLoops() int {
+	var (
+		loops   int
+		visited = make(map[*GluHalfEdge]bool)
+	)
+	for edge := range g.Edges() {
+		for _, e := range [2]*GluHalfEdge{edge, edge.Sym} {
+			if !isBoundary(e) || visited[e] {
+				continue
+			}
+			loops++
+			for !visited[e] {
+				visited[e] = true
+
+				// The next boundary edge leaves the destination vertex of
+				// this one: starting with LNext, turn clockwise around that
+				// vertex until the face on the right is outside.
+				e = e.LNext
+				for !isBoundary(e) {
+					e = e.OPrev()
+				}
+			}
+		}
+	}
+	return loops
+}
+
+// EulerCharacteristic returns V - E + F for the mesh, where V, E and F are the
+// number of vertices, edges (not half-edges) and faces.
+func (g *GluMesh) EulerCharacteristic() int {
+	n := 0
+	for range g.Vertices() {
+		n++
+	}
+	for range g.Edges() {
+		n--
+	}
+	for range g.Faces() {
+		n++
+	}
+	return n
+}
+
+// closed tells whether every half-edge of the mesh has a left face, which is
+// no longer true once faces have been removed from it.
+func (g *GluMesh) closed() bool {
+	for e := range g.Edges() {
+		if e.LFace == nil || e.RFace() == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// components returns the number of connected pieces of the mesh.
+func (g *GluMesh) components() int {
+	parent := make(map[*GluVertex]*GluVertex)
+	find := func(v *GluVertex) *GluVertex {
+		r := v
+		for parent[r] != r {
+			r = parent[r]
+		}
+		for parent[v] != r {
+			parent[v], v = r, parent[v]
+		}
+		return r
+	}
+
+	n := 0
+	for v := range g.Vertices() {
+		parent[v] = v
+		n++
+	}
+	for e := range g.Edges() {
+		if a, b := find(e.Org), find(e.Dst()); a != b {
+			parent[a] = b
+			n--
+		}
+	}
+	return n
+}
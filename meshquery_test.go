@@ -0,0 +1,198 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+// meshFaces returns the faces of the mesh in list order. For meshes built by
+// newTestMesh, these are the triangles in order, then the outside faces.
+func meshFaces(g *GluMesh) []*GluFace {
+	var faces []*GluFace
+	for f := range g.Faces() {
+		faces = append(faces, f)
+	}
+	return faces
+}
+
+func TestFaceQueries(t *testing.T) {
+	// The triangles of gridMesh(1) are (0,0) (1,0) (1,1) and (0,0) (1,1)
+	// (0,1), followed by the clockwise outside face. Coords get a Z of S+T and
+	// are scaled in X, so that Bounds and STBounds differ.
+	g := gridMesh(1)
+	for v := range g.Vertices() {
+		v.Coords = [3]float32{2 * v.S, v.T, v.S + v.T}
+	}
+	tests := []struct {
+		name         string
+		area         float32
+		cs, ct       float32
+		min, max     [3]float32
+		stMin, stMax [2]float32
+	}{
+		{"lower triangle", 0.5, 2. / 3, 1. / 3, [3]float32{0, 0, 0}, [3]float32{2, 1, 2}, [2]float32{0, 0}, [2]float32{1, 1}},
+		{"upper triangle", 0.5, 1. / 3, 2. / 3, [3]float32{0, 0, 0}, [3]float32{2, 1, 2}, [2]float32{0, 0}, [2]float32{1, 1}},
+		{"outside", -1, 0.5, 0.5, [3]float32{0, 0, 0}, [3]float32{2, 1, 2}, [2]float32{0, 0}, [2]float32{1, 1}},
+	}
+	faces := meshFaces(g)
+	if len(faces) != len(tests) {
+		t.Fatalf("got %d faces, want %d", len(faces), len(tests))
+	}
+	for i, tc := range tests {
+		f := faces[i]
+		if got := f.Area(); got != tc.area {
+			t.Errorf("%s: Area = %g, want %g", tc.name, got, tc.area)
+		}
+		if cs, ct := f.Centroid(); math.Abs(float64(cs-tc.cs)) > 1e-6 || math.Abs(float64(ct-tc.ct)) > 1e-6 {
+			t.Errorf("%s: Centroid = (%g, %g), want (%g, %g)", tc.name, cs, ct, tc.cs, tc.ct)
+		}
+		if min, max := f.Bounds(); min != tc.min || max != tc.max {
+			t.Errorf("%s: Bounds = %v, %v, want %v, %v", tc.name, min, max, tc.min, tc.max)
+		}
+		if min, max := f.STBounds(); min != tc.stMin || max != tc.stMax {
+			t.Errorf("%s: STBounds = %v, %v, want %v, %v", tc.name, min, max, tc.stMin, tc.stMax)
+		}
+	}
+}
+
+func TestCentroidDegenerate(t *testing.T) {
+	// The triangle's vertices are collinear, so it encloses no area and its
+	// centroid is the average of its vertices.
+	g := newTestMesh([][2]float32{{0, 0}, {1, 0}, {5, 0}}, [][3]int{{0, 1, 2}})
+	f := meshFaces(g)[0]
+	if a := f.Area(); a != 0 {
+		t.Fatalf("Area = %g, want 0", a)
+	}
+	if s, t0 := f.Centroid(); s != 2 || t0 != 0 {
+		t.Errorf("Centroid = (%g, %g), want (2, 0)", s, t0)
+	}
+}
+
+func TestMeshBounds(t *testing.T) {
+	g := gridMesh(2)
+	for v := range g.Vertices() {
+		v.S, v.T = v.S-1, 3*v.T
+		v.Coords[2] = -v.T
+	}
+	if min, max := g.Bounds(); min != [3]float32{0, 0, -6} || max != [3]float32{2, 2, 0} {
+		t.Errorf("Bounds = %v, %v, want [0 0 -6], [2 2 0]", min, max)
+	}
+	if min, max := g.STBounds(); min != [2]float32{-1, 0} || max != [2]float32{1, 6} {
+		t.Errorf("STBounds = %v, %v, want [-1 0], [1 6]", min, max)
+	}
+
+	inf := float32(math.Inf(1))
+	empty := NewGluMesh()
+	if min, max := empty.Bounds(); min != [3]float32{inf, inf, inf} || max != [3]float32{-inf, -inf, -inf} {
+		t.Errorf("empty mesh: Bounds = %v, %v, want +Inf, -Inf", min, max)
+	}
+	if min, max := empty.STBounds(); min != [2]float32{inf, inf} || max != [2]float32{-inf, -inf} {
+		t.Errorf("empty mesh: STBounds = %v, %v, want +Inf, -Inf", min, max)
+	}
+}
+
+func TestValence(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []int // In vertex order, that is row by row from the bottom.
+	}{
+		{1, []int{3, 2, 2, 3}},
+		{2, []int{3, 4, 2, 4, 6, 4, 2, 4, 3}},
+	}
+	for _, tc := range tests {
+		i := 0
+		for v := range gridMesh(tc.n).Vertices() {
+			if got := v.Valence(); got != tc.want[i] {
+				t.Errorf("gridMesh(%d): vertex %d (%g, %g): Valence = %d, want %d", tc.n, i, v.S, v.T, got, tc.want[i])
+			}
+			i++
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	var (
+		faces   = meshFaces(gridMesh(1))
+		lower   = faces[0]
+		upper   = faces[1]
+		outside = faces[2]
+	)
+	tests := []struct {
+		name string
+		f    *GluFace
+		want []*GluFace
+	}{
+		// Each triangle shares its diagonal with the other one, and its two
+		// remaining edges with the outside face.
+		{"lower triangle", lower, []*GluFace{upper, outside}},
+		{"upper triangle", upper, []*GluFace{lower, outside}},
+		{"outside", outside, []*GluFace{lower, upper}},
+	}
+	for _, tc := range tests {
+		var got []*GluFace
+		for n := range tc.f.Neighbors() {
+			got = append(got, n)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %d neighbors, want %d", tc.name, len(got), len(tc.want))
+			continue
+		}
+		for _, w := range tc.want {
+			found := false
+			for _, n := range got {
+				found = found || n == w
+			}
+			if !found {
+				t.Errorf("%s: missing neighbor %p", tc.name, w)
+			}
+		}
+	}
+
+	// Once the outside face is removed, it is reported as a nil neighbor.
+	g := gridMesh(1)
+	removeOutsideFaces(g)
+	var nils int
+	for n := range meshFaces(g)[0].Neighbors() {
+		if n == nil {
+			nils++
+		}
+	}
+	if nils != 1 {
+		t.Errorf("after removing the outside face: got %d nil neighbors, want 1", nils)
+	}
+}
+
+func TestBoundaryLoops(t *testing.T) {
+	var (
+		middle = map[[2]int]bool{{1, 1}: true}
+		corner = map[[2]int]bool{{1, 1}: true, {2, 1}: true, {1, 2}: true, {2, 2}: true}
+	)
+	tests := []struct {
+		name  string
+		g     *GluMesh
+		loops int
+	}{
+		{"square", gridMesh(3), 1},
+		// The missing middle square is a hole with a boundary of its own.
+		{"square with a hole", holedGridMesh(3, middle), 2},
+		{"square with two holes", holedGridMesh(5, map[[2]int]bool{{1, 1}: true, {2, 1}: true, {3, 3}: true}), 3},
+		// Without the upper right 2×2 squares, an L-shaped region remains.
+		{"L shape", holedGridMesh(3, corner), 1},
+	}
+	for _, tc := range tests {
+		if got := tc.g.BoundaryLoops(); got != tc.loops {
+			t.Errorf("%s: BoundaryLoops = %d, want %d", tc.name, got, tc.loops)
+		}
+	}
+
+	// Holes count the same once the outside faces are removed.
+	g := holedGridMesh(3, middle)
+	removeOutsideFaces(g)
+	if got := g.BoundaryLoops(); got != 2 {
+		t.Errorf("square with a hole, outside removed: BoundaryLoops = %d, want 2", got)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// The rasterizers below sample every pixel once, at its center, and use S and
+// T (or the contour coordinates) directly as pixel X and Y coordinates. Pixels
+// are set to the fill color rather than blended, so that the output of
+// GluMesh.Rasterize and RasterizeContours can be compared pixel by pixel.
+
+// Rasterize fills the inside faces of the mesh into img with the color c.
+//
+// Triangles are filled with a top-left style rule: a pixel center exactly on
+// an edge shared by two triangles is filled by exactly one of them, so there
+// are neither gaps nor double hits along shared edges. Inside faces which are
+// not triangles are filled with a scanline fill of their edge loop.
+func (g *GluMesh) Rasterize(img *image.RGBA, c color.Color) {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	for f := range g.Faces() {
+		if !f.Inside {
+			continue
+		}
+		if isTriangle(f) {
+			rasterizeTriangle(img, f, rgba)
+			continue
+		}
+		var loop [][2]float32
+		for e := range f.Edges() {
+			loop = append(loop, [2]float32{e.Org.S, e.Org.T})
+		}
+		fillContours(img, [][][2]float32{loop}, WindingNonZero, rgba)
+	}
+}
+
+// RasterizeContours fills the region of the contours which is inside under
+// the given winding rule into img with the color c, using a scanline fill.
+// The contours are not tessellated, so this serves as a reference to compare
+// the output of GluMesh.Rasterize against.
+func RasterizeContours(img *image.RGBA, contours [][][2]float32, rule WindingRule, c color.Color) {
+	fillContours(img, contours, rule, color.RGBAModel.Convert(c).(color.RGBA))
+}
+
+// rasterizeTriangle fills the pixels whose centers lie inside the triangular
+// face f.
+func rasterizeTriangle(img *image.RGBA, f *GluFace, c color.RGBA) {
+	v := [3]*GluVertex{f.AnEdge.Org, f.AnEdge.LNext.Org, f.AnEdge.LNext.LNext.Org}
+	if orient(v[0], v[1], float64(v[2].S), float64(v[2].T)) < 0 {
+		// Make the interior lie to the left of each edge.
+		v[1], v[2] = v[2], v[1]
+	}
+
+	// Clip the triangle's bounding box to the image.
+	b := img.Bounds()
+	minX, maxX := float64(b.Max.X), float64(b.Min.X)
+	minY, maxY := float64(b.Max.Y), float64(b.Min.Y)
+	for _, p := range v {
+		minX, maxX = math.Min(minX, float64(p.S)), math.Max(maxX, float64(p.S))
+		minY, maxY = math.Min(minY, float64(p.T)), math.Max(maxY, float64(p.T))
+	}
+	x0 := int(math.Max(float64(b.Min.X), math.Floor(minX-0.5)))
+	x1 := int(math.Min(float64(b.Max.X-1), math.Ceil(maxX-0.5)))
+	y0 := int(math.Max(float64(b.Min.Y), math.Floor(minY-0.5)))
+	y1 := int(math.Min(float64(b.Max.Y-1), math.Ceil(maxY-0.5)))
+
+	// A pixel center on an edge belongs to the triangle only for edges
+	// pointing in one half of all directions. The triangle on the other side
+	// traverses a shared edge in the opposite direction, so exactly one of the
+	// two claims the pixel. With the interior on the left, the owned edges are
+	// those along which T decreases and the horizontal ones along which S
+	// increases: the left and top edges in image coordinates, where T grows
+	// downwards. fillContours follows the same convention.
+	var owns [3]bool
+	for i := range v {
+		a, b := v[i], v[(i+1)%3]
+		ds, dt := b.S-a.S, b.T-a.T
+		owns[i] = dt < 0 || (dt == 0 && ds > 0)
+	}
+
+	for y := y0; y <= y1; y++ {
+		t := float64(y) + 0.5
+	pixels:
+		for x := x0; x <= x1; x++ {
+			s := float64(x) + 0.5
+			for i := range v {
+				w := orient(v[i], v[(i+1)%3], s, t)
+				if w < 0 || (w == 0 && !owns[i]) {
+					continue pixels
+				}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// fillContours fills the pixels whose centers lie inside the contours under
+// the winding rule, one row of pixel centers at a time. As with the triangle
+// rule of Rasterize, centers on the left and top edges of the region (in image
+// coordinates) are filled, and those on its right and bottom edges are not.
+func fillContours(img *image.RGBA, contours [][][2]float32, rule WindingRule, c color.RGBA) {
+	var (
+		b     = img.Bounds()
+		edges = contourEdges(contours)
+		spans [][2]float64
+	)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		spans = appendInsideSpans(spans[:0], edges, rule, float64(y)+0.5)
+		for _, sp := range spans {
+			// Fill the pixels with centers in [sp[0], sp[1]).
+			x0 := int(math.Max(float64(b.Min.X), math.Ceil(sp[0]-0.5)))
+			x1 := int(math.Min(float64(b.Max.X), math.Ceil(sp[1]-0.5)))
+			for x := x0; x < x1; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
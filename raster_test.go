@@ -0,0 +1,108 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+var rasterColor = color.RGBA{0xff, 0, 0, 0xff}
+
+// filledPixels returns the pixels of img set to rasterColor, as a string of
+// rows from the top, with '#' for a filled pixel and '.' for an empty one.
+func filledPixels(img *image.RGBA) string {
+	var (
+		b = img.Bounds()
+		s []byte
+	)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y) == rasterColor {
+				s = append(s, '#')
+			} else {
+				s = append(s, '.')
+			}
+		}
+		s = append(s, '\n')
+	}
+	return string(s)
+}
+
+// compareRasters fills the inside faces of g and the contours into images of
+// the given size, and fails the test unless both fills match.
+func compareRasters(t *testing.T, name string, g *GluMesh, contours [][][2]float32, rule WindingRule, size int) string {
+	t.Helper()
+	var (
+		mesh = image.NewRGBA(image.Rect(0, 0, size, size))
+		ref  = image.NewRGBA(image.Rect(0, 0, size, size))
+	)
+	g.Rasterize(mesh, rasterColor)
+	RasterizeContours(ref, contours, rule, rasterColor)
+	got, want := filledPixels(mesh), filledPixels(ref)
+	if got != want {
+		t.Errorf("%s: Rasterize filled\n%swhile RasterizeContours filled\n%s", name, got, want)
+	}
+	return got
+}
+
+func TestRasterizeSquare(t *testing.T) {
+	// The edges of the square, and its diagonal, run through pixel centers.
+	var (
+		square = [][2]float32{{0.5, 0.5}, {2.5, 0.5}, {2.5, 2.5}, {0.5, 2.5}}
+		g      = newTestMesh(square, [][3]int{{0, 1, 2}, {0, 2, 3}})
+		golden = "" +
+			"##..\n" +
+			"##..\n" +
+			"....\n" +
+			"....\n"
+	)
+	if got := compareRasters(t, "square", g, [][][2]float32{square}, WindingNonZero, 4); got != golden {
+		t.Errorf("filled\n%swant\n%s", got, golden)
+	}
+}
+
+func TestRasterizeWindingRules(t *testing.T) {
+	// Two overlapping squares A and B, with B either counter-clockwise or
+	// clockwise, on a 3×3 grid of cells whose edges run through pixel
+	// centers. The mesh triangulates every cell, and its inside faces are
+	// chosen by the winding number of the contours around them.
+	var (
+		a    = [][2]float32{{0.5, 0.5}, {4.5, 0.5}, {4.5, 4.5}, {0.5, 4.5}}
+		bCCW = [][2]float32{{2.5, 2.5}, {6.5, 2.5}, {6.5, 6.5}, {2.5, 6.5}}
+		bCW  = [][2]float32{{2.5, 2.5}, {2.5, 6.5}, {6.5, 6.5}, {6.5, 2.5}}
+	)
+	rules := []struct {
+		name string
+		rule WindingRule
+	}{
+		{"odd", WindingOdd},
+		{"nonzero", WindingNonZero},
+		{"positive", WindingPositive},
+		{"negative", WindingNegative},
+		{"abs>=2", WindingAbsGeqTwo},
+	}
+	for i, contours := range [][][][2]float32{{a, bCCW}, {a, bCW}} {
+		for _, r := range rules {
+			g := gridMesh(3)
+			for v := range g.Vertices() {
+				v.S, v.T = 2*v.S+0.5, 2*v.T+0.5
+			}
+			for f := range g.Faces() {
+				if !isTriangle(f) {
+					continue // The outside face.
+				}
+				cs, ct := f.Centroid()
+				f.Inside = r.rule.IsInside(windingNumber(contours, float64(cs), float64(ct)))
+			}
+			name := r.name + " (B counter-clockwise)"
+			if i == 1 {
+				name = r.name + " (B clockwise)"
+			}
+			compareRasters(t, name, g, contours, r.rule, 8)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+)
+
+// SVGOptions controls the image written by GluMesh.WriteSVG.
+type SVGOptions struct {
+	// Width is the width of the image in pixels, the height follows from the
+	// aspect ratio of the mesh. If zero, a width of 512 is used.
+	Width int
+
+	// InsideFill and OutsideFill are the SVG colors used to fill inside and
+	// outside faces. If empty, light blue and light gray are used.
+	InsideFill, OutsideFill string
+
+	// Labels, if true, labels each vertex with its index in the vertex list.
+	Labels bool
+
+	// Mark, if non-nil, is called for each vertex and the vertices for which
+	// it returns true are highlighted; for instance the vertices a caller
+	// knows to have been created at edge intersections.
+	Mark func(v *GluVertex) bool
+}
+
+// WriteSVG writes the mesh to w as an SVG image, for debugging.
+//
+// Vertices are placed at their S/T coordinates, with T pointing up. Faces are
+// filled according to their Inside flag, except for faces with a clockwise
+// loop: these are the unbounded faces surrounding each connected piece of the
+// mesh, and filling them would cover the whole piece. Boundary edges, which
+// separate an inside face from an outside or removed (nil) one, are drawn
+// thick and all other edges thin. If opts is nil, the zero options are used.
+func (g *GluMesh) WriteSVG(w io.Writer, opts *SVGOptions) error {
+	if opts == nil {
+		opts = &SVGOptions{}
+	}
+	var (
+		width       = opts.Width
+		insideFill  = opts.InsideFill
+		outsideFill = opts.OutsideFill
+	)
+	if width == 0 {
+		width = 512
+	}
+	if insideFill == "" {
+		insideFill = "lightblue"
+	}
+	if outsideFill == "" {
+		outsideFill = "lightgray"
+	}
+
+	// Find the bounds of the mesh and a transform fitting them into the image
+	// with a small margin.
+	minS, minT := math.Inf(1), math.Inf(1)
+	maxS, maxT := math.Inf(-1), math.Inf(-1)
+	for v := range g.Vertices() {
+		minS, maxS = math.Min(minS, float64(v.S)), math.Max(maxS, float64(v.S))
+		minT, maxT = math.Min(minT, float64(v.T)), math.Max(maxT, float64(v.T))
+	}
+	if minS > maxS {
+		minS, minT, maxS, maxT = 0, 0, 1, 1
+	}
+	size := math.Max(maxS-minS, maxT-minT)
+	if size == 0 {
+		size = 1
+	}
+	const margin = 16
+	scale := float64(width-2*margin) / size
+	height := int(math.Ceil((maxT-minT)*scale)) + 2*margin
+	pt := func(v *GluVertex) (x, y float64) {
+		return margin + (float64(v.S)-minS)*scale, float64(height) - margin - (float64(v.T)-minT)*scale
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	fmt.Fprintln(bw, "<rect width=\"100%\" height=\"100%\" fill=\"white\"/>")
+
+	// Faces.
+	for f := range g.Faces() {
+		if faceArea(f) < 0 {
+			continue
+		}
+		fill := outsideFill
+		if f.Inside {
+			fill = insideFill
+		}
+		fmt.Fprint(bw, "<polygon points=\"")
+		for e := range f.Edges() {
+			x, y := pt(e.Org)
+			fmt.Fprintf(bw, "%.2f,%.2f ", x, y)
+		}
+		fmt.Fprintf(bw, "\" fill=\"%s\" stroke=\"none\"/>\n", fill)
+	}
+
+	// Edges.
+	for e := range g.Edges() {
+		var (
+			x0, y0 = pt(e.Org)
+			x1, y1 = pt(e.Dst())
+			style  = "stroke=\"gray\" stroke-width=\"0.5\""
+		)
+		if isBoundary(e) || isBoundary(e.Sym) {
+			style = "stroke=\"black\" stroke-width=\"2\""
+		}
+		fmt.Fprintf(bw, "<line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" %s/>\n", x0, y0, x1, y1, style)
+	}
+
+	// Vertices.
+	i := 0
+	for v := range g.Vertices() {
+		x, y := pt(v)
+		if opts.Mark != nil && opts.Mark(v) {
+			fmt.Fprintf(bw, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"5\" fill=\"none\" stroke=\"red\" stroke-width=\"2\"/>\n", x, y)
+		}
+		fmt.Fprintf(bw, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"2\" fill=\"black\"/>\n", x, y)
+		if opts.Labels {
+			fmt.Fprintf(bw, "<text x=\"%.2f\" y=\"%.2f\" font-family=\"monospace\" font-size=\"10\">%d</text>\n", x+4, y-4, i)
+		}
+		i++
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
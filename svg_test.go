@@ -0,0 +1,126 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+// svgElements parses the SVG image and returns the number of elements of each
+// kind; lines and circles are further counted by their stroke-width and
+// stroke, and polygons by their fill.
+func svgElements(t *testing.T, img []byte) map[string]int {
+	t.Helper()
+	var (
+		d     = xml.NewDecoder(bytes.NewReader(img))
+		count = make(map[string]int)
+		root  string
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("malformed SVG: %v\n%s", err, img)
+		}
+		el, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root == "" {
+			root = el.Name.Local
+		}
+		attr := make(map[string]string)
+		for _, a := range el.Attr {
+			attr[a.Name.Local] = a.Value
+		}
+		count[el.Name.Local]++
+		switch el.Name.Local {
+		case "line":
+			count["line width="+attr["stroke-width"]]++
+		case "circle":
+			count["circle stroke="+attr["stroke"]]++
+		case "polygon":
+			count["polygon fill="+attr["fill"]]++
+		}
+	}
+	if root != "svg" {
+		t.Fatalf("root element is %q, want svg", root)
+	}
+	return count
+}
+
+func TestWriteSVG(t *testing.T) {
+	removed := gridMesh(1)
+	removeOutsideFaces(removed)
+
+	tests := []struct {
+		name string
+		g    *GluMesh
+		opts *SVGOptions
+		want map[string]int
+	}{
+		{
+			name: "empty",
+			g:    NewGluMesh(),
+			want: map[string]int{"svg": 1, "rect": 1},
+		},
+		{
+			// The outside face has a clockwise loop and is not filled.
+			name: "grid",
+			g:    gridMesh(1),
+			want: map[string]int{
+				"svg": 1, "rect": 1,
+				"polygon": 2, "polygon fill=lightblue": 2,
+				"line": 5, "line width=2": 4, "line width=0.5": 1,
+				"circle": 4, "circle stroke=": 4,
+			},
+		},
+		{
+			name: "removed face",
+			g:    removed,
+			opts: &SVGOptions{InsideFill: "red", Labels: true},
+			want: map[string]int{
+				"svg": 1, "rect": 1,
+				"polygon": 2, "polygon fill=red": 2,
+				"line": 5, "line width=2": 4, "line width=0.5": 1,
+				"circle": 4, "circle stroke=": 4,
+				"text": 4,
+			},
+		},
+		{
+			name: "marked",
+			g:    gridMesh(1),
+			opts: &SVGOptions{Mark: func(v *GluVertex) bool { return v.S == 0 }},
+			want: map[string]int{
+				"svg": 1, "rect": 1,
+				"polygon": 2, "polygon fill=lightblue": 2,
+				"line": 5, "line width=2": 4, "line width=0.5": 1,
+				"circle": 6, "circle stroke=": 4, "circle stroke=red": 2,
+			},
+		},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := tc.g.WriteSVG(&buf, tc.opts); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		got := svgElements(t, buf.Bytes())
+		for k, n := range tc.want {
+			if got[k] != n {
+				t.Errorf("%s: got %d %q elements, want %d", tc.name, got[k], k, n)
+			}
+		}
+		for k, n := range got {
+			if _, ok := tc.want[k]; !ok {
+				t.Errorf("%s: got %d unexpected %q elements", tc.name, n, k)
+			}
+		}
+	}
+}
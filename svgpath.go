@@ -0,0 +1,344 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SVGPathError is returned by ParseSVGPath for malformed path data.
+type SVGPathError struct {
+	// Offset is the byte offset in the path data at which the problem was
+	// found.
+	Offset int
+
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *SVGPathError) Error() string {
+	return fmt.Sprintf("tess: invalid SVG path at offset %d: %s", e.Offset, e.Reason)
+}
+
+// ParseFillRule returns the winding rule for the value of an SVG fill-rule
+// property: WindingNonZero for "nonzero" (and for an empty value, as that is
+// the SVG default) and WindingOdd for "evenodd".
+func ParseFillRule(s string) (WindingRule, error) {
+	switch strings.TrimSpace(s) {
+	case "", "nonzero":
+		return WindingNonZero, nil
+	case "evenodd":
+		return WindingOdd, nil
+	}
+	return 0, fmt.Errorf("tess: invalid SVG fill-rule %q", s)
+}
+
+// ParseSVGPath parses SVG path data, the d attribute of a path element, into
+// contours to be filled with the winding rule of the path's fill-rule (see
+// ParseFillRule). All commands are supported, in both their absolute and
+// relative forms: M, L, H, V, C, S, Q, T, A and Z. Curves and arcs are
+// flattened to within tol using AppendCubic, AppendQuadratic and
+// AppendEndpointArc.
+//
+// Each subpath becomes one contour, closed implicitly as SVG does when filling
+// (a closing vertex equal to the first one is dropped). Subpaths with fewer
+// than three vertices enclose no area and are omitted. Coordinates are kept
+// as they are, that is with Y pointing down; filling is not affected by this.
+//
+// Feeding the contours to the BeginContour and AddVertex calls of
+// GluTesselator is left until it is ported; until then they can be used with
+// RasterizeContours and GluMesh.Validate.
+func ParseSVGPath(d string, tol float32) ([][][2]float32, error) {
+	var (
+		p        = svgPathParser{d: d}
+		contours [][][2]float32
+		cur      [][3]float32
+		pos      [3]float32 // The current point.
+		start    [3]float32 // The start of the current subpath.
+		ctrl     [3]float32 // The last control point, for S and T.
+		last     byte       // The last command, in upper case.
+	)
+	closeSubpath := func() {
+		if n := len(cur); n > 1 && cur[n-1] == cur[0] {
+			cur = cur[:n-1]
+		}
+		if len(cur) >= 3 {
+			c := make([][2]float32, len(cur))
+			for i, v := range cur {
+				c[i] = [2]float32{v[0], v[1]}
+			}
+			contours = append(contours, c)
+		}
+		cur = nil
+	}
+	lineTo := func(v [3]float32) {
+		if cur == nil {
+			// A command following Z starts a new subpath at the start of
+			// the closed one.
+			cur = [][3]float32{pos}
+		}
+		if cur[len(cur)-1] != v {
+			cur = append(cur, v)
+		}
+	}
+
+	for {
+		p.skipSpace()
+		if p.i == len(d) {
+			break
+		}
+		cmd := d[p.i]
+		if !strings.ContainsRune("MmLlHhVvCcSsQqTtAaZz", rune(cmd)) {
+			return nil, p.errorf("unexpected %q", cmd)
+		}
+		upper, rel := cmd&^0x20, cmd >= 'a'
+		if last == 0 && upper != 'M' {
+			return nil, p.errorf("path data must start with a moveto")
+		}
+		p.i++
+
+		if upper == 'Z' {
+			closeSubpath()
+			pos, last = start, 'Z'
+			continue
+		}
+
+		// Commands repeat for as long as arguments follow; extra coordinate
+		// pairs after a moveto are linetos.
+		for first := true; first || p.more(); first = false {
+			var origin [3]float32
+			if rel {
+				origin = pos
+			}
+			switch upper {
+			case 'M':
+				v, err := p.point(origin)
+				if err != nil {
+					return nil, err
+				}
+				if first {
+					closeSubpath()
+					cur, start = [][3]float32{v}, v
+				} else {
+					lineTo(v)
+				}
+				pos = v
+
+			case 'L':
+				v, err := p.point(origin)
+				if err != nil {
+					return nil, err
+				}
+				lineTo(v)
+				pos = v
+
+			case 'H', 'V':
+				x, err := p.number()
+				if err != nil {
+					return nil, err
+				}
+				v := pos
+				if upper == 'H' {
+					v[0] = origin[0] + x
+				} else {
+					v[1] = origin[1] + x
+				}
+				lineTo(v)
+				pos = v
+
+			case 'C', 'S':
+				var (
+					pts [3][3]float32
+					err error
+				)
+				i := 0
+				if upper == 'S' {
+					// The first control point is the reflection of the
+					// previous curve's second one.
+					pts[0] = pos
+					if last == 'C' || last == 'S' {
+						pts[0] = [3]float32{2*pos[0] - ctrl[0], 2*pos[1] - ctrl[1], 0}
+					}
+					i = 1
+				}
+				for ; i < 3; i++ {
+					if pts[i], err = p.point(origin); err != nil {
+						return nil, err
+					}
+				}
+				lineTo(pos)
+				cur = AppendCubic(cur, pos, pts[0], pts[1], pts[2], tol)
+				pos, ctrl = pts[2], pts[1]
+
+			case 'Q', 'T':
+				var (
+					c, v [3]float32
+					err  error
+				)
+				if upper == 'Q' {
+					if c, err = p.point(origin); err != nil {
+						return nil, err
+					}
+				} else {
+					c = pos
+					if last == 'Q' || last == 'T' {
+						c = [3]float32{2*pos[0] - ctrl[0], 2*pos[1] - ctrl[1], 0}
+					}
+				}
+				if v, err = p.point(origin); err != nil {
+					return nil, err
+				}
+				lineTo(pos)
+				cur = AppendQuadratic(cur, pos, c, v, tol)
+				pos, ctrl = v, c
+
+			case 'A':
+				var args [3]float32
+				for i := range args {
+					x, err := p.number()
+					if err != nil {
+						return nil, err
+					}
+					args[i] = x
+				}
+				largeArc, err := p.flag()
+				if err != nil {
+					return nil, err
+				}
+				sweep, err := p.flag()
+				if err != nil {
+					return nil, err
+				}
+				v, err := p.point(origin)
+				if err != nil {
+					return nil, err
+				}
+				var (
+					rx       = float32(math.Abs(float64(args[0])))
+					ry       = float32(math.Abs(float64(args[1])))
+					rotation = args[2] * math.Pi / 180
+				)
+				lineTo(pos)
+				cur = AppendEndpointArc(cur, pos, v, rx, ry, rotation, largeArc, sweep, tol)
+				pos = v
+			}
+			last = upper
+		}
+	}
+	closeSubpath()
+	return contours, nil
+}
+
+// svgPathParser scans the arguments of SVG path data commands.
+type svgPathParser struct {
+	d string
+	i int
+}
+
+func (p *svgPathParser) errorf(format string, args ...interface{}) error {
+	return &SVGPathError{Offset: p.i, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (p *svgPathParser) skipSpace() {
+	for p.i < len(p.d) && strings.IndexByte(" \t\n\r\f", p.d[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// skipSeparator skips white space and at most one comma.
+func (p *svgPathParser) skipSeparator() {
+	p.skipSpace()
+	if p.i < len(p.d) && p.d[p.i] == ',' {
+		p.i++
+		p.skipSpace()
+	}
+}
+
+// more tells whether another argument follows.
+func (p *svgPathParser) more() bool {
+	p.skipSeparator()
+	if p.i == len(p.d) {
+		return false
+	}
+	c := p.d[p.i]
+	return c == '+' || c == '-' || c == '.' || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// number scans a number. Numbers need no separator where the next one starts
+// with a sign or a second decimal point, as in "1-2" or "0.5.5".
+func (p *svgPathParser) number() (float32, error) {
+	p.skipSeparator()
+	var (
+		d      = p.d
+		i      = p.i
+		digits bool
+	)
+	if i < len(d) && (d[i] == '+' || d[i] == '-') {
+		i++
+	}
+	for ; i < len(d) && isDigit(d[i]); i++ {
+		digits = true
+	}
+	if i < len(d) && d[i] == '.' {
+		for i++; i < len(d) && isDigit(d[i]); i++ {
+			digits = true
+		}
+	}
+	if !digits {
+		if p.i == len(d) {
+			return 0, p.errorf("unexpected end of path data, expected a number")
+		}
+		return 0, p.errorf("expected a number")
+	}
+	if i < len(d) && (d[i] == 'e' || d[i] == 'E') {
+		// An exponent needs digits, otherwise the e is not part of the
+		// number.
+		j := i + 1
+		if j < len(d) && (d[j] == '+' || d[j] == '-') {
+			j++
+		}
+		if j < len(d) && isDigit(d[j]) {
+			for i = j; i < len(d) && isDigit(d[i]); i++ {
+			}
+		}
+	}
+	f, err := strconv.ParseFloat(d[p.i:i], 32)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", d[p.i:i])
+	}
+	p.i = i
+	return float32(f), nil
+}
+
+// point scans a coordinate pair, relative to origin.
+func (p *svgPathParser) point(origin [3]float32) ([3]float32, error) {
+	x, err := p.number()
+	if err != nil {
+		return [3]float32{}, err
+	}
+	y, err := p.number()
+	if err != nil {
+		return [3]float32{}, err
+	}
+	return [3]float32{origin[0] + x, origin[1] + y}, nil
+}
+
+// flag scans an arc flag, a single 0 or 1 which needs no separator from what
+// follows it.
+func (p *svgPathParser) flag() (bool, error) {
+	p.skipSeparator()
+	if p.i < len(p.d) && (p.d[p.i] == '0' || p.d[p.i] == '1') {
+		p.i++
+		return p.d[p.i-1] == '1', nil
+	}
+	return false, p.errorf("expected an arc flag")
+}
@@ -0,0 +1,193 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseSVGPath(t *testing.T) {
+	var (
+		square = [][][2]float32{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}
+		holed  = [][][2]float32{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+			{{1, 1}, {3, 1}, {3, 3}, {1, 3}},
+		}
+	)
+	tests := []struct {
+		d    string
+		want [][][2]float32
+	}{
+		{"M0 0 L10 0 L10 10 L0 10 Z", square},
+		{"M 0,0 L 10,0 L 10,10 L 0,10 L 0,0 Z", square},
+		{"m0,0 10,0 0,10 -10,0z", square},
+		{"M0 0H10V10H0Z", square},
+		{"M0,0h10v10h-10z", square},
+		{"M0 0L10 0 10 10 0 10", square},
+		{"M0 0L10 0 10 10 0 10 0 0", square},
+		{"M0 0L10 0L10 0L10 10L0 10z", square},
+		{"M+0-0l1e1,0 0 10E0 -10 .0z", square},
+		{"M.5.5L1-2l1e1,0", [][][2]float32{{{0.5, 0.5}, {1, -2}, {11, -2}}}},
+		{"M0 0h4v4h-4z m1 1h2v2h-2z", holed},
+		{"M0 0h4v4h-4zM1 1h2v2h-2z", holed},
+		{"M0 0h4v4h-4z l1 0 0 1", [][][2]float32{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+			{{0, 0}, {1, 0}, {1, 1}},
+		}},
+		{"M0 0 M5 5 L6 5 6 6", [][][2]float32{{{5, 5}, {6, 5}, {6, 6}}}},
+		{"M0 0 L1 1 Z M5 5", nil},
+		{"", nil},
+		{"  ", nil},
+	}
+	for _, tc := range tests {
+		got, err := ParseSVGPath(tc.d, 0.1)
+		if err != nil {
+			t.Errorf("%q: %v", tc.d, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %v, want %v", tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestParseSVGPathCurves(t *testing.T) {
+	// Each pair of paths describes the same curves, so they must flatten to
+	// the same contours.
+	tests := [][2]string{
+		{"M0 0C0 10 10 10 10 0S20 -10 20 0", "M0 0C0 10 10 10 10 0C10 -10 20 -10 20 0"},
+		{"M0 0C0 10 10 10 10 0S20 -10 20 0", "m0 0c0 10 10 10 10 0s10 -10 10 0"},
+		{"M0 0L5 5S10 10 20 0", "M0 0L5 5C5 5 10 10 20 0"},
+		{"M0 0Q5 10 10 0T20 0", "M0 0Q5 10 10 0Q15 -10 20 0"},
+		{"M0 0Q5 10 10 0T20 0", "m0 0q5 10 10 0t10 0"},
+		{"M0 0L5 5T20 0", "M0 0L5 5Q5 5 20 0"},
+		{"M0 0C0 10 10 10 10 0T20 0", "M0 0C0 10 10 10 10 0Q10 0 20 0"},
+		{"M0 0A5 5 0 1 1 10 0A5 5 0 1 1 0 0Z", "M0 0a5 5 0 1110 0a5,5,0,1,1-10,0z"},
+		{"M0 0A5 5 0 0 0 10 0Z", "M0 0A-5-5 0 0 0 10 0Z"},
+	}
+	for _, tc := range tests {
+		a, err := ParseSVGPath(tc[0], 0.01)
+		if err != nil {
+			t.Fatalf("%q: %v", tc[0], err)
+		}
+		b, err := ParseSVGPath(tc[1], 0.01)
+		if err != nil {
+			t.Fatalf("%q: %v", tc[1], err)
+		}
+		if len(a) == 0 || !reflect.DeepEqual(a, b) {
+			t.Errorf("%q and %q differ:\n%v\n%v", tc[0], tc[1], a, b)
+		}
+	}
+}
+
+func TestParseSVGPathArcs(t *testing.T) {
+	tests := []struct {
+		d    string
+		area float64
+	}{
+		// A circle of radius 5 from two half-circle arcs.
+		{"M0 0A5 5 0 1 1 10 0A5 5 0 1 1 0 0Z", 25 * math.Pi},
+		// The same circle drawn the other way around.
+		{"M0 0A5 5 0 1 0 10 0A5 5 0 1 0 0 0Z", -25 * math.Pi},
+		// Radii too small to span the end points are scaled up.
+		{"M0 0A1 1 0 0 1 10 0Z", 12.5 * math.Pi},
+		// A quarter of an ellipse rotated by 90 degrees, plus the triangle
+		// closing it through its center at (0, 0).
+		{"M0 -20A20 10 90 0 1 10 0L0 0Z", 50 * math.Pi},
+		// Zero radii degrade to straight lines.
+		{"M0 0A0 5 0 0 1 10 0L10 10Z", 50},
+	}
+	for _, tol := range []float32{0.1, 0.01} {
+		for _, tc := range tests {
+			c, err := ParseSVGPath(tc.d, tol)
+			if err != nil {
+				t.Fatalf("%q: %v", tc.d, err)
+			}
+			if len(c) != 1 {
+				t.Fatalf("%q: got %d contours, want 1", tc.d, len(c))
+			}
+			// The polygon can lose at most tol times the perimeter, which is
+			// below 100 for all of the above.
+			if a := contourArea(c[0]); math.Abs(a-tc.area) > 100*float64(tol) {
+				t.Errorf("%q: tol=%g: area %g, want %g", tc.d, tol, a, tc.area)
+			}
+		}
+	}
+}
+
+func TestParseSVGPathErrors(t *testing.T) {
+	tests := []struct {
+		d      string
+		offset int
+	}{
+		{"L0 0", 0},
+		{"0 0", 0},
+		{"M0", 2},
+		{"M0 0 X", 5},
+		{"M0 0 L1", 7},
+		{"M0 0 L1 2,,3 4", 10},
+		{"M0 0 Z 1 1", 7},
+		{"M0 0 A1 1 0 2 0 1 1", 12},
+		{"M0 0 L1e 2", 7},
+		{"M0 0 L1e999 2", 6},
+		{"M0 0 L- 2", 6},
+	}
+	for _, tc := range tests {
+		_, err := ParseSVGPath(tc.d, 0.1)
+		var perr *SVGPathError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: got error %v, want an *SVGPathError", tc.d, err)
+			continue
+		}
+		if perr.Offset != tc.offset {
+			t.Errorf("%q: got offset %d (%v), want %d", tc.d, perr.Offset, err, tc.offset)
+		}
+	}
+}
+
+func TestParseFillRule(t *testing.T) {
+	tests := []struct {
+		s    string
+		want WindingRule
+	}{
+		{"", WindingNonZero},
+		{"nonzero", WindingNonZero},
+		{" evenodd ", WindingOdd},
+	}
+	for _, tc := range tests {
+		got, err := ParseFillRule(tc.s)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseFillRule(%q) = %v, %v, want %v", tc.s, got, err, tc.want)
+		}
+	}
+	if _, err := ParseFillRule("inherit"); err == nil {
+		t.Error("ParseFillRule(\"inherit\") succeeded")
+	}
+}
+
+func TestParseSVGPathFill(t *testing.T) {
+	// A square with a hole drawn in the same direction, which is a hole under
+	// evenodd but not under nonzero.
+	const d = "M0 0h4v4h-4z M1 1h2v2h-2z"
+	c, err := ParseSVGPath(d, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		rule string
+		area float64
+	}{{"nonzero", 16}, {"evenodd", 12}} {
+		rule, err := ParseFillRule(tc.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if area := insideArea(c, rule); math.Abs(area-tc.area) > 1e-9 {
+			t.Errorf("%s: area %g, want %g", tc.rule, area, tc.area)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ValidationError is returned by GluMesh.Validate when the tessellation does
+// not match its input.
+type ValidationError struct {
+	// Face is the offending face, or nil if the error concerns the mesh as a
+	// whole.
+	Face *GluFace
+
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return "tess: invalid tessellation: " + e.Reason
+}
+
+// Validate checks the inside faces of a triangulated mesh against the
+// contours it was built from, which are given in the same S/T plane as the
+// mesh (for a normal of (0, 0, 1) these are simply the X and Y coordinates of
+// the input). It verifies that:
+//
+//   - every inside face is a triangle of positive area, that is it is neither
+//     degenerate nor inverted;
+//   - the centroid of every inside triangle is inside the contours under the
+//     given winding rule;
+//   - the summed area of the inside triangles matches the area of the input
+//     which is inside under the winding rule.
+//
+// The area of the input is integrated exactly and independently of the mesh,
+// so the comparison only allows for a small relative error. A
+// *ValidationError describing the first problem found is returned, or nil if
+// there is none.
+func (g *GluMesh) Validate(contours [][][2]float32, rule WindingRule) error {
+	var area float64
+	for f := range g.Faces() {
+		if !f.Inside {
+			continue
+		}
+		if !isTriangle(f) {
+			return &ValidationError{Face: f, Reason: "inside face is not a triangle"}
+		}
+		a := faceArea(f)
+		if a <= 0 {
+			return &ValidationError{Face: f, Reason: fmt.Sprintf("triangle is degenerate or inverted (area %g)", a)}
+		}
+		area += a
+
+		var (
+			v0 = f.AnEdge.Org
+			v1 = f.AnEdge.LNext.Org
+			v2 = f.AnEdge.LNext.LNext.Org
+			s  = (float64(v0.S) + float64(v1.S) + float64(v2.S)) / 3
+			t  = (float64(v0.T) + float64(v1.T) + float64(v2.T)) / 3
+		)
+		if n := windingNumber(contours, s, t); !rule.IsInside(n) {
+			return &ValidationError{Face: f, Reason: fmt.Sprintf("triangle centroid (%g, %g) has winding number %d, which is outside", s, t, n)}
+		}
+	}
+
+	// The input area is exact, so only allow for round-off, and for vertices
+	// the tessellation created at edge intersections being rounded to
+	// float32.
+	want := insideArea(contours, rule)
+	if math.Abs(area-want) > 1e-4*math.Max(area, want) {
+		return &ValidationError{Reason: fmt.Sprintf("triangle area %g does not match input area %g", area, want)}
+	}
+	return nil
+}
+
+// insideArea returns the area of the region which is inside the contours under
+// the given winding rule.
+//
+// The area is integrated exactly, in slabs between the distinct T coordinates
+// of the contour vertices and of the points where edges cross each other.
+// Within such a slab every edge spans its whole height and no two edges swap
+// places, so the inside length of a horizontal line varies linearly and its
+// value at the middle of the slab times the slab's height is exact.
+func insideArea(contours [][][2]float32, rule WindingRule) float64 {
+	edges := contourEdges(contours)
+	ts := make([]float64, 0, 2*len(edges))
+	for _, e := range edges {
+		ts = append(ts, e.at, e.bt)
+	}
+	sort.Float64s(ts)
+	sort.Slice(edges, func(i, j int) bool {
+		return math.Min(edges[i].at, edges[i].bt) < math.Min(edges[j].at, edges[j].bt)
+	})
+
+	var (
+		area   float64
+		active []contourEdge
+		next   int
+		cuts   []float64
+		spans  [][2]float64
+	)
+	for i := 0; i+1 < len(ts); i++ {
+		t0, t1 := ts[i], ts[i+1]
+		if t0 == t1 {
+			continue
+		}
+
+		// Keep the edges spanning [t0, t1]: as the slab starts at a vertex,
+		// they are those ending above t0 and starting at or below it.
+		keep := active[:0]
+		for _, e := range active {
+			if math.Max(e.at, e.bt) > t0 {
+				keep = append(keep, e)
+			}
+		}
+		active = keep
+		for ; next < len(edges) && math.Min(edges[next].at, edges[next].bt) <= t0; next++ {
+			active = append(active, edges[next])
+		}
+
+		cuts = appendIntersections(append(cuts[:0], t0, t1), active, t0, t1)
+		sort.Float64s(cuts)
+		for j := 0; j+1 < len(cuts); j++ {
+			a, b := cuts[j], cuts[j+1]
+			if a == b {
+				continue
+			}
+			spans = appendInsideSpans(spans[:0], active, rule, (a+b)/2)
+			for _, sp := range spans {
+				area += (sp[1] - sp[0]) * (b - a)
+			}
+		}
+	}
+	return area
+}
+
+// appendIntersections appends to dst the T coordinates at which pairs of the
+// edges, all of which span the slab from t0 to t1, cross inside the slab.
+// These are the pairs whose order along S differs at t0 and at t1; sorting
+// the edges from one order into the other by insertion swaps each such pair
+// exactly once.
+func appendIntersections(dst []float64, edges []contourEdge, t0, t1 float64) []float64 {
+	type ends struct{ s0, s1 float64 }
+	p := make([]ends, len(edges))
+	for i := range edges {
+		p[i] = ends{edges[i].sAt(t0), edges[i].sAt(t1)}
+	}
+	sort.Slice(p, func(i, j int) bool {
+		if p[i].s0 != p[j].s0 {
+			return p[i].s0 < p[j].s0
+		}
+		return p[i].s1 < p[j].s1
+	})
+	for i := 1; i < len(p); i++ {
+		for j := i; j > 0 && p[j-1].s1 > p[j].s1; j-- {
+			// a is left of b at t0, and right of it at t1.
+			a, b := p[j-1], p[j]
+			d0, d1 := b.s0-a.s0, a.s1-b.s1
+			dst = append(dst, t0+(t1-t0)*d0/(d0+d1))
+			p[j-1], p[j] = b, a
+		}
+	}
+	return dst
+}
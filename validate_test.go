@@ -0,0 +1,114 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var (
+		square = [][][2]float32{{{0, 0}, {3, 0}, {3, 3}, {0, 3}}}
+		g      = gridMesh(3)
+	)
+	if err := g.Validate(square, WindingNonZero); err != nil {
+		t.Fatal(err)
+	}
+
+	// With one triangle missing, the area no longer matches.
+	for f := range g.Faces() {
+		if f.Inside {
+			f.Inside = false
+			break
+		}
+	}
+	if err := g.Validate(square, WindingNonZero); err == nil {
+		t.Error("mesh with a missing triangle validated")
+	}
+
+	// Under the negative rule, no triangle is inside.
+	if err := gridMesh(3).Validate(square, WindingNegative); err == nil {
+		t.Error("mesh validated under the negative winding rule")
+	}
+
+	// With a hole in the middle cell, the triangles covering it are outside.
+	holed := [][][2]float32{square[0], {{1, 1}, {1, 2}, {2, 2}, {2, 1}}}
+	var verr *ValidationError
+	if err := gridMesh(3).Validate(holed, WindingNonZero); !errors.As(err, &verr) || verr.Face == nil {
+		t.Errorf("got %v, want a *ValidationError for a face in the hole", err)
+	}
+}
+
+func TestValidateThin(t *testing.T) {
+	// Tall, thin rectangles, upright and rotated by 45 degrees, split into
+	// two triangles.
+	var (
+		d      = float32(100 / math.Sqrt2)
+		w      = float32(0.1 / math.Sqrt2)
+		shapes = map[string][][2]float32{
+			"upright": {{0, 0}, {0.1, 0}, {0.1, 100}, {0, 100}},
+			"rotated": {{0, 0}, {w, -w}, {w + d, d - w}, {d, d}},
+		}
+	)
+	for name, points := range shapes {
+		contours := [][][2]float32{points}
+		if err := newTestMesh(points, [][3]int{{0, 1, 2}, {0, 2, 3}}).Validate(contours, WindingNonZero); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+
+		for _, inside := range [][2]bool{{true, false}, {false, true}, {false, false}} {
+			var (
+				g = newTestMesh(points, [][3]int{{0, 1, 2}, {0, 2, 3}})
+				i = 0
+			)
+			for f := range g.Faces() {
+				if f.Inside {
+					f.Inside = inside[i]
+					i++
+				}
+			}
+			var verr *ValidationError
+			if err := g.Validate(contours, WindingNonZero); !errors.As(err, &verr) {
+				t.Errorf("%s: inside faces %v: got %v, want a *ValidationError", name, inside, err)
+			}
+		}
+	}
+}
+
+func TestInsideArea(t *testing.T) {
+	var (
+		a      = [][2]float32{{0, 0}, {4, 0}, {4, 4}, {0, 4}}
+		b      = [][2]float32{{2, 2}, {6, 2}, {6, 6}, {2, 6}}
+		bowtie = [][2]float32{{0, 0}, {2, 2}, {2, 0}, {0, 2}}
+		tri    = [][2]float32{{0, 0}, {3, 1}, {1, 4}}
+	)
+	tests := []struct {
+		name     string
+		contours [][][2]float32
+		rule     WindingRule
+		want     float64
+	}{
+		{"empty", nil, WindingNonZero, 0},
+		{"triangle", [][][2]float32{tri}, WindingNonZero, 5.5},
+		{"overlap nonzero", [][][2]float32{a, b}, WindingNonZero, 28},
+		{"overlap odd", [][][2]float32{a, b}, WindingOdd, 24},
+		{"overlap abs>=2", [][][2]float32{a, b}, WindingAbsGeqTwo, 4},
+		{"overlap negative", [][][2]float32{a, b}, WindingNegative, 0},
+		// The halves of the bowtie wind in opposite directions, and its
+		// edges cross between vertices.
+		{"bowtie nonzero", [][][2]float32{bowtie}, WindingNonZero, 2},
+		{"bowtie positive", [][][2]float32{bowtie}, WindingPositive, 1},
+		{"bowtie negative", [][][2]float32{bowtie}, WindingNegative, 1},
+		{"thin", [][][2]float32{{{0, 0}, {0.1, 0}, {0.1, 100}, {0, 100}}}, WindingNonZero, 10},
+	}
+	for _, tc := range tests {
+		// The input coordinates are float32, so 0.1 is not exact.
+		if got := insideArea(tc.contours, tc.rule); math.Abs(got-tc.want) > 1e-6*math.Max(1, tc.want) {
+			t.Errorf("%s: got %g, want %g", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2014 The Tess Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tess
+
+import "sort"
+
+// WindingRule determines which parts of the polygon are on the interior,
+// based on their winding number: the signed number of times the contours wind
+// around them (counter-clockwise contours count positively).
+type WindingRule int
+
+// The values match the GLU_TESS_WINDING_* enums used by libtess.
+const (
+	WindingOdd       WindingRule = 100130
+	WindingNonZero   WindingRule = 100131
+	WindingPositive  WindingRule = 100132
+	WindingNegative  WindingRule = 100133
+	WindingAbsGeqTwo WindingRule = 100134
+)
+
+// IsInside tells whether a region with the given winding number is inside the
+// polygon under this rule.
+func (r WindingRule) IsInside(n int) bool {
+	switch r {
+	case WindingOdd:
+		return n&1 != 0
+	case WindingNonZero:
+		return n != 0
+	case WindingPositive:
+		return n > 0
+	case WindingNegative:
+		return n < 0
+	case WindingAbsGeqTwo:
+		return n >= 2 || n <= -2
+	}
+	panic("tess: invalid winding rule")
+}
+
+// windingNumber returns the winding number of the contours (given in the S/T
+// plane) around the point (s, t).
+func windingNumber(contours [][][2]float32, s, t float64) int {
+	n := 0
+	for _, c := range contours {
+		for i := range c {
+			var (
+				a  = c[i]
+				b  = c[(i+1)%len(c)]
+				as = float64(a[0])
+				at = float64(a[1])
+				bs = float64(b[0])
+				bt = float64(b[1])
+				// Positive if (s, t) is left of the edge a-b.
+				side = (bs-as)*(t-at) - (s-as)*(bt-at)
+			)
+			if at <= t {
+				if bt > t && side > 0 {
+					n++
+				}
+			} else if bt <= t && side < 0 {
+				n--
+			}
+		}
+	}
+	return n
+}
+
+// contourEdge is an edge of a contour in the S/T plane, running from (as, at)
+// to (bs, bt). Its dir is 1 if it points up (towards greater T), -1 if it
+// points down.
+type contourEdge struct {
+	as, at, bs, bt float64
+	dir            int
+}
+
+// contourEdges returns the edges of the contours, leaving out horizontal ones
+// as they never cross a horizontal line.
+func contourEdges(contours [][][2]float32) []contourEdge {
+	var edges []contourEdge
+	for _, c := range contours {
+		for i := range c {
+			var (
+				a = c[i]
+				b = c[(i+1)%len(c)]
+				e = contourEdge{
+					as: float64(a[0]), at: float64(a[1]),
+					bs: float64(b[0]), bt: float64(b[1]),
+					dir: 1,
+				}
+			)
+			if e.at == e.bt {
+				continue
+			}
+			if e.at > e.bt {
+				e.dir = -1
+			}
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// sAt returns the S coordinate at which the edge crosses the horizontal line
+// at t.
+func (e *contourEdge) sAt(t float64) float64 {
+	return e.as + (t-e.at)*(e.bs-e.as)/(e.bt-e.at)
+}
+
+// appendInsideSpans appends to dst the spans of the horizontal line at t which
+// are inside the edges (see contourEdges) under the winding rule, from left to
+// right. Each span [s0, s1) starts and ends where the line crosses an edge; an
+// edge whose end point lies exactly on the line counts as crossing it only if
+// the other end is above.
+func appendInsideSpans(dst [][2]float64, edges []contourEdge, rule WindingRule, t float64) [][2]float64 {
+	type crossing struct {
+		s   float64
+		dir int
+	}
+	var xing []crossing
+	for i := range edges {
+		e := &edges[i]
+		if (e.at <= t) == (e.bt <= t) {
+			continue
+		}
+		xing = append(xing, crossing{s: e.sAt(t), dir: e.dir})
+	}
+	sort.Slice(xing, func(i, j int) bool { return xing[i].s < xing[j].s })
+
+	// Walk the line from left to right. Crossing an upward edge leaves the
+	// region it winds around, a downward one enters it.
+	w := 0
+	for i := 0; i+1 < len(xing); i++ {
+		w -= xing[i].dir
+		if rule.IsInside(w) {
+			dst = append(dst, [2]float64{xing[i].s, xing[i+1].s})
+		}
+	}
+	return dst
+}